@@ -1,19 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 
+	"hema-lessons/internal/assets"
 	"hema-lessons/internal/config"
 	"hema-lessons/internal/handlers"
+	"hema-lessons/internal/imaging"
 	"hema-lessons/internal/middleware"
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/router"
 	"hema-lessons/internal/store"
 )
 
@@ -73,105 +78,103 @@ func main() {
 		}
 	}
 
-	dataStore, err := store.New()
+	// Book assets get content-hashed URLs, which the store serves in place of
+	// the logical ones and which can be cached forever.
+	assetsFS := os.DirFS(cfg.Assets.Dir)
+	manifest, err := assets.NewManifest(assetsFS, "/assets/", "books")
+	if err != nil {
+		slog.Error("failed to build asset manifest", "dir", cfg.Assets.Dir, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("asset manifest built", "assets", manifest.Len())
+
+	// Images the content refers to are measured and given placeholders
+	// whenever the content is loaded.
+	storeOpts := []store.Option{
+		store.WithImages(imaging.NewCatalog(assetsFS, "/assets/")),
+		store.WithAssetManifest(manifest),
+	}
+
+	dataStore, err := loadStore(cfg, storeOpts...)
 	if err != nil {
 		// #region agent log
 		debugLog("main.go:store.New", "store load FAILED", "H-B", map[string]interface{}{"error": err.Error()})
 		// #endregion
-		slog.Error("failed to load data store", "error", err)
+		// A validation report goes to stderr once, in full, and the log
+		// only counts its problems.
+		var verr *store.ValidationError
+		if errors.As(err, &verr) {
+			fmt.Fprintln(os.Stderr, verr.Error())
+			slog.Error("failed to load data store: content failed validation", "problems", len(verr.Problems))
+		} else {
+			slog.Error("failed to load data store", "error", err)
+		}
 		os.Exit(1)
 	}
 	// #region agent log
 	debugLog("main.go:store.New", "store loaded OK - new binary is running", "H-A", map[string]interface{}{"built": "post-rename"})
 	// #endregion
-	slog.Info("data store loaded")
+	slog.Info("data store loaded", "data_dir", cfg.Store.DataDir)
 
-	resourceHandler := handlers.NewResourceHandler(dataStore)
-	sectionHandler := handlers.NewSectionHandler(dataStore)
-	itemHandler := handlers.NewItemHandler(dataStore)
+	// Only the memory backend serves the data directory directly; the sqlite
+	// backend copies it into the database, so edits are not hot reloaded.
+	switch {
+	case cfg.Store.DataDir == "":
+	case cfg.Store.Backend == config.StoreBackendMemory:
+		if err := dataStore.Watch(context.Background(), cfg.Store.DataDir); err != nil {
+			slog.Error("failed to watch data directory", "error", err)
+			os.Exit(1)
+		}
+	default:
+		slog.Warn("data directory is not watched: hot reload needs the memory store backend",
+			"data_dir", cfg.Store.DataDir, "backend", cfg.Store.Backend)
+	}
 
-	mux := http.NewServeMux()
+	repo, err := openRepository(cfg, dataStore, storeOpts...)
+	if err != nil {
+		slog.Error("failed to open content repository", "backend", cfg.Store.Backend, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("content repository ready", "backend", cfg.Store.Backend)
 
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
+	imageCache, err := imaging.OpenDiskCache(cfg.Assets.ImageCacheDir, int64(cfg.Assets.ImageCacheSize))
+	if err != nil {
+		slog.Error("failed to open image cache", "dir", cfg.Assets.ImageCacheDir, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("image cache ready", "dir", cfg.Assets.ImageCacheDir, "bytes", imageCache.Size())
 
+	// Content only changes with the data, so API responses can be revalidated
+	// against the repository's version.
+	routes := handlers.Routes(repo)
+	for i, rt := range routes {
+		routes[i].Handler = middleware.ConditionalGET(repo, cfg.Server.CacheControl, rt.Handler).ServeHTTP
+	}
+	routes = append(routes,
+		router.Route{Method: http.MethodGet, Path: "/healthz", Handler: healthzHandler(cfg)},
+		router.Route{Method: http.MethodGet, Path: "/assets/", Handler: manifest.Handler(imaging.Handler("/assets/", cfg.Assets.Dir, imageCache)).ServeHTTP},
+	)
+	apiRouter := router.New(routes, func(w http.ResponseWriter, r *http.Request) {
 		// #region agent log
-		debugLog("main.go:router", "incoming request", "H-D", map[string]interface{}{"method": r.Method, "path": path})
+		debugLog("main.go:notfound", "request fell through to 404", "H-D", map[string]interface{}{"path": r.URL.Path, "method": r.Method})
 		// #endregion
+		problem.RouteNotFound(w, r)
+	})
 
-		if strings.HasPrefix(path, "/assets/") {
-			http.StripPrefix("/assets/", http.FileServer(http.Dir("assets"))).ServeHTTP(w, r)
-			return
-		}
-
-		if path == "/healthz" {
-			healthzHandler(cfg)(w, r)
-			return
-		}
-
-		// GET /api/resources
-		if path == "/api/resources" || path == "/api/resources/" {
-			if r.Method == http.MethodGet {
-				resourceHandler.List(w, r)
-				return
-			}
-		}
-
-		if strings.HasPrefix(path, "/api/resources/") {
-			remaining := path[len("/api/resources/"):]
-			parts := strings.SplitN(remaining, "/", 2)
-
-			if len(parts) == 1 {
-				// GET /api/resources/:id
-				if r.Method == http.MethodGet {
-					resourceHandler.Get(w, r)
-					return
-				}
-			} else if len(parts) == 2 && parts[1] == "sections" {
-				// GET /api/resources/:id/sections
-				if r.Method == http.MethodGet {
-					sectionHandler.ListByBook(w, r)
-					return
-				}
-			}
-		}
-
-		if strings.HasPrefix(path, "/api/sections/") {
-			remaining := path[len("/api/sections/"):]
-			parts := strings.SplitN(remaining, "/", 2)
-
-			if len(parts) == 1 {
-				// GET /api/sections/:id
-				if r.Method == http.MethodGet {
-					sectionHandler.Get(w, r)
-					return
-				}
-			} else if len(parts) == 2 {
-				switch parts[1] {
-				case "sections":
-					// GET /api/sections/:id/sections
-					if r.Method == http.MethodGet {
-						sectionHandler.ListChildren(w, r)
-						return
-					}
-				case "items":
-					// GET /api/sections/:id/items
-					if r.Method == http.MethodGet {
-						itemHandler.ListBySection(w, r)
-						return
-					}
-				}
-			}
-		}
-
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// #region agent log
-		debugLog("main.go:notfound", "request fell through to 404", "H-D", map[string]interface{}{"path": path, "method": r.Method})
+		debugLog("main.go:router", "incoming request", "H-D", map[string]interface{}{"method": r.Method, "path": r.URL.Path})
 		// #endregion
-		http.NotFound(w, r)
+		apiRouter.ServeHTTP(w, r)
 	})
 
-	// Wrap handler with middleware (order: Recovery -> RequestLogger -> mux)
-	httpHandler := middleware.Recovery(middleware.RequestLogger(mux))
+	compressed := middleware.Compress(middleware.CompressOptions{
+		MinSize:   cfg.Server.CompressMinSize,
+		CacheSize: cfg.Server.CompressCacheSize,
+	}, mux)
+
+	// Wrap handler with middleware (order: RequestID -> Recovery -> RequestLogger -> Compress -> mux)
+	httpHandler := middleware.RequestID(middleware.Recovery(middleware.RequestLogger(compressed)))
 
 	server := &http.Server{
 		Addr:              cfg.Server.Addr,
@@ -186,6 +189,58 @@ func main() {
 	}
 }
 
+// loadStore parses the JSON data files from cfg.Store.DataDir, or from the
+// files embedded in the binary when no directory is configured.
+func loadStore(cfg *config.Config, opts ...store.Option) (*store.Store, error) {
+	if cfg.Store.DataDir == "" {
+		return store.New(opts...)
+	}
+	return store.Load(os.DirFS(cfg.Store.DataDir), opts...)
+}
+
+// openRepository returns the content repository selected by cfg.Store.Backend.
+// An empty SQLite database is seeded from the embedded JSON store, and one
+// whose content predates its schema is re-synced from it.
+func openRepository(cfg *config.Config, embedded *store.Store, opts ...store.Option) (store.ContentRepository, error) {
+	if cfg.Store.Backend != config.StoreBackendSQLite {
+		return embedded, nil
+	}
+
+	db, err := store.OpenSQLite(cfg.Store.SQLitePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	empty, err := db.IsEmpty()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if empty {
+		if err := db.ImportFrom(embedded); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("importing embedded content: %w", err)
+		}
+		slog.Info("imported embedded content into sqlite", "path", cfg.Store.SQLitePath)
+		return db, nil
+	}
+
+	outdated, err := db.ContentOutdated()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if outdated {
+		if err := db.SyncFrom(embedded); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("re-syncing embedded content: %w", err)
+		}
+		slog.Info("re-synced sqlite content after a schema upgrade", "path", cfg.Store.SQLitePath)
+	}
+
+	return db, nil
+}
+
 func healthzHandler(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		resp := healthResponse{
@@ -199,7 +254,7 @@ func healthzHandler(cfg *config.Config) http.HandlerFunc {
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			problem.Internal(w, r, "failed to encode response")
 		}
 	}
 }
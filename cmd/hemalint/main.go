@@ -0,0 +1,55 @@
+// Command hemalint checks the content data files against the asset tree.
+//
+// Usage:
+//
+//	go run ./cmd/hemalint [-data internal/store/data] [-assets assets] [-format text|json] [-strict]
+//
+// It exits with status 1 when errors are found (or warnings, with -strict)
+// and 2 when the data cannot be read.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"hema-lessons/internal/lint"
+)
+
+func main() {
+	dataDir := flag.String("data", "internal/store/data", "directory holding the JSON data files")
+	assetsDir := flag.String("assets", "assets", "directory served under /assets/")
+	format := flag.String("format", "text", "output format: text or json")
+	strict := flag.Bool("strict", false, "exit non-zero on warnings as well as errors")
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "hemalint: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	report, err := lint.Run(*dataDir, *assetsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hemalint: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "hemalint: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		for _, f := range report.Findings {
+			fmt.Println(f)
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		os.Exit(1)
+	}
+}
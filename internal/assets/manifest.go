@@ -0,0 +1,252 @@
+// Package assets fingerprints the files served from /assets/. A Manifest maps
+// each file's logical URL, such as /assets/books/fechtbuch/cover/cover.jpg,
+// to a URL carrying a hash of its content, such as
+// /assets/books/fechtbuch/cover/cover.3f9c0a1b2c3d4e5f.jpg. A changed file
+// gets a new URL, so the fingerprinted URLs can be cached forever. A file
+// that changes while the server runs is hashed again the next time it is
+// looked up or requested, and its old URLs redirect to the new one.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"hema-lessons/internal/problem"
+)
+
+// ImmutableCacheControl is sent with responses to fingerprinted URLs.
+const ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+// RevalidateCacheControl is sent with responses to logical URLs, whose
+// content may change.
+const RevalidateCacheControl = "public, no-cache"
+
+// hashLen is the number of hex digits of the content hash put in URLs.
+const hashLen = 16
+
+// Manifest maps logical asset URLs to fingerprinted ones. A nil *Manifest
+// maps every URL to itself. It is safe for concurrent use.
+type Manifest struct {
+	fsys   fs.FS
+	prefix string
+
+	mu    sync.RWMutex
+	urls  map[string]string // logical URL -> current fingerprinted URL
+	files map[string]file   // fingerprinted URL, current or stale -> file
+}
+
+// file is the file a fingerprinted URL was made from, as it was when hashed.
+type file struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// NewManifest hashes every file under dir in fsys, which is served at URLs
+// starting with prefix. A missing dir yields an empty manifest.
+func NewManifest(fsys fs.FS, prefix, dir string) (*Manifest, error) {
+	m := &Manifest{
+		fsys:   fsys,
+		prefix: prefix,
+		urls:   make(map[string]string),
+		files:  make(map[string]file),
+	}
+
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, err = m.hash(name, info)
+		return err
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("building asset manifest: %w", err)
+	}
+	return m, nil
+}
+
+// hash fingerprints the file name, whose stat is info, and makes the result
+// its current URL.
+func (m *Manifest) hash(name string, info fs.FileInfo) (string, error) {
+	sum, err := hashFile(m.fsys, name)
+	if err != nil {
+		return "", err
+	}
+	hashed := m.prefix + fingerprint(name, sum)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.urls[m.prefix+name] = hashed
+	m.files[hashed] = file{name: name, size: info.Size(), modTime: info.ModTime()}
+	return hashed, nil
+}
+
+// current returns the fingerprinted URL of the file name as it is now,
+// hashing it again when its size or modification time differ from when it
+// was last hashed. It returns false when the file can no longer be read.
+func (m *Manifest) current(name string) (string, bool) {
+	m.mu.RLock()
+	hashed := m.urls[m.prefix+name]
+	f := m.files[hashed]
+	m.mu.RUnlock()
+
+	info, err := fs.Stat(m.fsys, name)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	if info.Size() == f.size && info.ModTime().Equal(f.modTime) {
+		return hashed, true
+	}
+	hashed, err = m.hash(name, info)
+	if err != nil {
+		return "", false
+	}
+	return hashed, true
+}
+
+func hashFile(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:hashLen], nil
+}
+
+// fingerprint puts hash before the extension of name: cover.jpg becomes
+// cover.<hash>.jpg.
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// URL returns the fingerprinted URL of the asset at logical, as its file is
+// now, or logical itself when the manifest does not hold it or the file is
+// gone.
+func (m *Manifest) URL(logical string) string {
+	if m == nil {
+		return logical
+	}
+	m.mu.RLock()
+	hashed, ok := m.urls[logical]
+	name := m.files[hashed].name
+	m.mu.RUnlock()
+	if !ok {
+		return logical
+	}
+	if hashed, ok = m.current(name); !ok {
+		return logical
+	}
+	return hashed
+}
+
+// Len returns the number of assets in the manifest.
+func (m *Manifest) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.urls)
+}
+
+// Entries returns the manifest as a map from logical to fingerprinted URLs.
+func (m *Manifest) Entries() map[string]string {
+	entries := make(map[string]string, m.Len())
+	if m != nil {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		for logical, hashed := range m.urls {
+			entries[logical] = hashed
+		}
+	}
+	return entries
+}
+
+// Handler serves fingerprinted URLs through next as if their logical URL had
+// been requested, with ImmutableCacheControl. Other URLs pass through with
+// RevalidateCacheControl. A fingerprinted URL whose file has changed since
+// it was hashed redirects with 302 Found to the file's current URL, keeping
+// the query, so stale content is never served under an immutable URL while
+// content loaded earlier can still refer to it. A fingerprinted URL whose
+// file is gone is not found. Cache-Control is left off error responses.
+func (m *Manifest) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		f, ok := m.files[r.URL.Path]
+		m.mu.RUnlock()
+		if !ok {
+			next.ServeHTTP(&cacheWriter{ResponseWriter: w, cacheControl: RevalidateCacheControl}, r)
+			return
+		}
+
+		hashed, ok := m.current(f.name)
+		if !ok {
+			problem.NotFound(w, r, "asset")
+			return
+		}
+		if hashed != r.URL.Path {
+			target := hashed
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusFound)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = m.prefix + f.name
+		r2.URL.RawPath = ""
+		next.ServeHTTP(&cacheWriter{ResponseWriter: w, cacheControl: ImmutableCacheControl}, r2)
+	})
+}
+
+// cacheWriter sets Cache-Control on successful and not-modified responses.
+type cacheWriter struct {
+	http.ResponseWriter
+	cacheControl string
+	wroteHeader  bool
+}
+
+func (cw *cacheWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if code < http.StatusBadRequest {
+			cw.Header().Set("Cache-Control", cw.cacheControl)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *cacheWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
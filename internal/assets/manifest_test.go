@@ -0,0 +1,198 @@
+package assets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testFS() fstest.MapFS {
+	modTime := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return fstest.MapFS{
+		"books/fechtbuch/cover/cover.jpg": {Data: []byte("cover"), ModTime: modTime},
+		"books/fechtbuch/plates/plate-1":  {Data: []byte("plate"), ModTime: modTime},
+		"other/logo.png":                  {Data: []byte("logo"), ModTime: modTime},
+	}
+}
+
+func TestNewManifest(t *testing.T) {
+	m, err := NewManifest(testFS(), "/assets/", "books")
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+
+	if m.Len() != 2 {
+		t.Errorf("expected 2 assets, got %d", m.Len())
+	}
+	if got := m.URL("/assets/books/fechtbuch/cover/cover.jpg"); !regexp.MustCompile(`^/assets/books/fechtbuch/cover/cover\.[0-9a-f]{16}\.jpg$`).MatchString(got) {
+		t.Errorf("unexpected fingerprinted URL %q", got)
+	}
+	if got := m.URL("/assets/books/fechtbuch/plates/plate-1"); !regexp.MustCompile(`^/assets/books/fechtbuch/plates/plate-1\.[0-9a-f]{16}$`).MatchString(got) {
+		t.Errorf("unexpected fingerprinted URL %q", got)
+	}
+	if got := m.URL("/assets/other/logo.png"); got != "/assets/other/logo.png" {
+		t.Errorf("expected an asset outside dir to keep its URL, got %q", got)
+	}
+	if got := m.URL("https://example.com/cover.jpg"); got != "https://example.com/cover.jpg" {
+		t.Errorf("expected an external URL to be kept, got %q", got)
+	}
+
+	// The hash covers the content, so equal files share it and changed files
+	// do not.
+	again, err := NewManifest(testFS(), "/assets/", "books")
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+	if again.URL("/assets/books/fechtbuch/cover/cover.jpg") != m.URL("/assets/books/fechtbuch/cover/cover.jpg") {
+		t.Error("expected the same content to get the same URL")
+	}
+	changed := testFS()
+	changed["books/fechtbuch/cover/cover.jpg"].Data = []byte("new cover")
+	edited, err := NewManifest(changed, "/assets/", "books")
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+	if edited.URL("/assets/books/fechtbuch/cover/cover.jpg") == m.URL("/assets/books/fechtbuch/cover/cover.jpg") {
+		t.Error("expected changed content to get a new URL")
+	}
+}
+
+func TestNewManifest_MissingDir(t *testing.T) {
+	m, err := NewManifest(testFS(), "/assets/", "missing")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("expected an empty manifest, got %d assets", m.Len())
+	}
+}
+
+func TestManifest_NilIsIdentity(t *testing.T) {
+	var m *Manifest
+	if got := m.URL("/assets/books/a.jpg"); got != "/assets/books/a.jpg" {
+		t.Errorf("expected the URL to be kept, got %q", got)
+	}
+	if m.Len() != 0 || len(m.Entries()) != 0 {
+		t.Error("expected a nil manifest to be empty")
+	}
+}
+
+func TestManifest_Handler(t *testing.T) {
+	fsys := testFS()
+	m, err := NewManifest(fsys, "/assets/", "books")
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+	h := m.Handler(http.StripPrefix("/assets/", http.FileServer(http.FS(fsys))))
+	hashed := m.URL("/assets/books/fechtbuch/cover/cover.jpg")
+
+	tests := []struct {
+		name                 string
+		path                 string
+		expectedStatus       int
+		expectedBody         string
+		expectedCacheControl string
+	}{
+		{
+			name:                 "fingerprinted URL",
+			path:                 hashed,
+			expectedStatus:       http.StatusOK,
+			expectedBody:         "cover",
+			expectedCacheControl: ImmutableCacheControl,
+		},
+		{
+			name:                 "logical URL",
+			path:                 "/assets/books/fechtbuch/cover/cover.jpg",
+			expectedStatus:       http.StatusOK,
+			expectedBody:         "cover",
+			expectedCacheControl: RevalidateCacheControl,
+		},
+		{
+			name:                 "asset outside the manifest",
+			path:                 "/assets/other/logo.png",
+			expectedStatus:       http.StatusOK,
+			expectedBody:         "logo",
+			expectedCacheControl: RevalidateCacheControl,
+		},
+		{
+			name:           "unknown file",
+			path:           "/assets/books/fechtbuch/cover/missing.jpg",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.expectedCacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", tt.expectedCacheControl, got)
+			}
+		})
+	}
+
+	t.Run("changed file", func(t *testing.T) {
+		fsys["books/fechtbuch/cover/cover.jpg"] = &fstest.MapFile{Data: []byte("new cover"), ModTime: time.Now()}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, hashed+"?w=320", nil))
+
+		current := m.URL("/assets/books/fechtbuch/cover/cover.jpg")
+		if current == hashed {
+			t.Fatal("expected the changed file to get a new URL")
+		}
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected status code %d, got %d", http.StatusFound, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != current+"?w=320" {
+			t.Errorf("expected a redirect to %q, got %q", current+"?w=320", got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "" {
+			t.Errorf("expected no Cache-Control, got %q", got)
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, current, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "new cover" {
+			t.Fatalf("expected the new cover, got %d %q", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Cache-Control"); got != ImmutableCacheControl {
+			t.Errorf("expected Cache-Control %q, got %q", ImmutableCacheControl, got)
+		}
+	})
+
+	t.Run("removed file", func(t *testing.T) {
+		plate := m.URL("/assets/books/fechtbuch/plates/plate-1")
+		delete(fsys, "books/fechtbuch/plates/plate-1")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, plate, nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "" {
+			t.Errorf("expected no Cache-Control, got %q", got)
+		}
+		var body struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode problem: %v", err)
+		}
+		if body.Code != "asset_not_found" {
+			t.Errorf("expected code asset_not_found, got %q", body.Code)
+		}
+	})
+}
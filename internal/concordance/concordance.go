@@ -0,0 +1,125 @@
+// Package concordance relates the versions of a technique found in different
+// treatises. Items joined by same or variant links, directly or through other
+// items, are versions of one technique; counter and prerequisite links relate
+// an item to other techniques.
+package concordance
+
+import (
+	"sort"
+
+	"hema-lessons/internal/models"
+)
+
+// Directions of a non-symmetric link, seen from the item it was looked up for.
+const (
+	// Outgoing: the looked-up item counters, or is a prerequisite of, the other.
+	Outgoing = "outgoing"
+	// Incoming: the other item counters, or is a prerequisite of, the looked-up one.
+	Incoming = "incoming"
+)
+
+// Version is another version of a technique. Relation is same if every link
+// on the way to it is a same link, and variant otherwise.
+type Version struct {
+	ItemID   int
+	Relation string
+	Note     string // note of the direct link, if there is one
+}
+
+// Related is an item linked by a counter or prerequisite link.
+type Related struct {
+	ItemID    int
+	Type      string
+	Direction string
+	Note      string
+}
+
+// Concordance indexes a set of links by item.
+type Concordance struct {
+	versions map[int][]models.ConcordanceLink // same and variant links, both directions
+	related  map[int][]Related
+}
+
+// New builds a Concordance from links. Links keep their order, which decides
+// the order of Related results.
+func New(links []models.ConcordanceLink) *Concordance {
+	c := &Concordance{
+		versions: make(map[int][]models.ConcordanceLink),
+		related:  make(map[int][]Related),
+	}
+	for _, l := range links {
+		if models.IsSymmetricConcordanceType(l.Type) {
+			c.versions[l.ItemID] = append(c.versions[l.ItemID], l)
+			c.versions[l.RelatedItemID] = append(c.versions[l.RelatedItemID], models.ConcordanceLink{
+				ItemID: l.RelatedItemID, RelatedItemID: l.ItemID, Type: l.Type, Note: l.Note,
+			})
+			continue
+		}
+		c.related[l.ItemID] = append(c.related[l.ItemID], Related{
+			ItemID: l.RelatedItemID, Type: l.Type, Direction: Outgoing, Note: l.Note,
+		})
+		c.related[l.RelatedItemID] = append(c.related[l.RelatedItemID], Related{
+			ItemID: l.ItemID, Type: l.Type, Direction: Incoming, Note: l.Note,
+		})
+	}
+	return c
+}
+
+// Versions returns the other versions of the technique taught by itemID,
+// ordered by item ID. A version reachable through same links only is
+// reported as same even if a variant path also exists.
+func (c *Concordance) Versions(itemID int) []Version {
+	relation := map[int]string{itemID: models.ConcordanceSame}
+	notes := make(map[int]string)
+	for _, l := range c.versions[itemID] {
+		if l.Note != "" {
+			notes[l.RelatedItemID] = l.Note
+		}
+	}
+
+	// Visit same links before variant ones so that an item is first reached
+	// by its strongest relation.
+	for _, pass := range []string{models.ConcordanceSame, models.ConcordanceVariant} {
+		queue := make([]int, 0, len(relation))
+		for id := range relation {
+			queue = append(queue, id)
+		}
+		sort.Ints(queue)
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, l := range c.versions[id] {
+				if _, seen := relation[l.RelatedItemID]; seen {
+					continue
+				}
+				if pass == models.ConcordanceSame && l.Type != models.ConcordanceSame {
+					continue
+				}
+				relation[l.RelatedItemID] = pass
+				queue = append(queue, l.RelatedItemID)
+			}
+		}
+	}
+
+	delete(relation, itemID)
+	out := make([]Version, 0, len(relation))
+	for id, rel := range relation {
+		out = append(out, Version{ItemID: id, Relation: rel, Note: notes[id]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ItemID < out[j].ItemID })
+	return out
+}
+
+// Related returns the counter and prerequisite links of itemID: links from
+// it first, then links to it, each in link order.
+func (c *Concordance) Related(itemID int) []Related {
+	var outgoing, incoming []Related
+	for _, r := range c.related[itemID] {
+		if r.Direction == Outgoing {
+			outgoing = append(outgoing, r)
+		} else {
+			incoming = append(incoming, r)
+		}
+	}
+	return append(outgoing, incoming...)
+}
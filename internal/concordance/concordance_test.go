@@ -0,0 +1,86 @@
+package concordance
+
+import (
+	"reflect"
+	"testing"
+
+	"hema-lessons/internal/models"
+)
+
+func testLinks() []models.ConcordanceLink {
+	return []models.ConcordanceLink{
+		{ItemID: 1, RelatedItemID: 2, Type: models.ConcordanceSame, Note: "Identical plays"},
+		{ItemID: 3, RelatedItemID: 2, Type: models.ConcordanceSame},
+		{ItemID: 2, RelatedItemID: 4, Type: models.ConcordanceVariant},
+		{ItemID: 4, RelatedItemID: 5, Type: models.ConcordanceSame},
+		{ItemID: 1, RelatedItemID: 5, Type: models.ConcordanceVariant},
+		{ItemID: 6, RelatedItemID: 1, Type: models.ConcordanceCounter, Note: "Breaks the play"},
+		{ItemID: 1, RelatedItemID: 7, Type: models.ConcordancePrerequisite},
+		{ItemID: 8, RelatedItemID: 1, Type: models.ConcordancePrerequisite},
+	}
+}
+
+func TestConcordance_Versions(t *testing.T) {
+	c := New(testLinks())
+
+	tests := []struct {
+		name   string
+		itemID int
+		want   []Version
+	}{
+		{
+			name:   "same links are transitive and variants taint the path",
+			itemID: 1,
+			want: []Version{
+				{ItemID: 2, Relation: models.ConcordanceSame, Note: "Identical plays"},
+				{ItemID: 3, Relation: models.ConcordanceSame},
+				{ItemID: 4, Relation: models.ConcordanceVariant},
+				{ItemID: 5, Relation: models.ConcordanceVariant},
+			},
+		},
+		{
+			name:   "symmetric links are followed backwards",
+			itemID: 5,
+			want: []Version{
+				{ItemID: 1, Relation: models.ConcordanceVariant},
+				{ItemID: 2, Relation: models.ConcordanceVariant},
+				{ItemID: 3, Relation: models.ConcordanceVariant},
+				{ItemID: 4, Relation: models.ConcordanceSame},
+			},
+		},
+		{
+			name:   "counter and prerequisite links are not versions",
+			itemID: 6,
+			want:   []Version{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Versions(tt.itemID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConcordance_Related(t *testing.T) {
+	c := New(testLinks())
+
+	want := []Related{
+		{ItemID: 7, Type: models.ConcordancePrerequisite, Direction: Outgoing},
+		{ItemID: 6, Type: models.ConcordanceCounter, Direction: Incoming, Note: "Breaks the play"},
+		{ItemID: 8, Type: models.ConcordancePrerequisite, Direction: Incoming},
+	}
+	if got := c.Related(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if got := c.Related(6); len(got) != 1 || got[0].ItemID != 1 || got[0].Direction != Outgoing {
+		t.Errorf("expected item 6 to counter item 1, got %+v", got)
+	}
+	if got := c.Related(2); len(got) != 0 {
+		t.Errorf("expected no related items for item 2, got %+v", got)
+	}
+}
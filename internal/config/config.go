@@ -3,17 +3,33 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
+const (
+	StoreBackendMemory = "memory"
+	StoreBackendSQLite = "sqlite"
+)
+
 type Config struct {
 	Server ServerConfig
 	App    AppConfig
+	Store  StoreConfig
+	Assets AssetsConfig
 }
 
 type ServerConfig struct {
 	Addr              string
 	ReadHeaderTimeout int
+	// CacheControl is sent with successful API responses, which carry an
+	// ETag and Last-Modified for revalidation.
+	CacheControl string
+	// CompressMinSize is the smallest response body, in bytes, that is
+	// compressed; CompressCacheSize bounds the compressed bodies kept for
+	// unchanged responses.
+	CompressMinSize   int
+	CompressCacheSize int
 }
 
 type AppConfig struct {
@@ -21,16 +37,43 @@ type AppConfig struct {
 	SentryDSN   string
 }
 
+type StoreConfig struct {
+	Backend    string
+	SQLitePath string
+	DataDir    string
+}
+
+// AssetsConfig locates the static assets and the disk cache of resized image
+// variants, which ImageCacheSize bounds in bytes.
+type AssetsConfig struct {
+	Dir            string
+	ImageCacheDir  string
+	ImageCacheSize int
+}
+
 func Load() (*Config, error) {
 	config := &Config{
 		Server: ServerConfig{
 			Addr:              getEnv("SERVER_ADDR", ":8080"),
 			ReadHeaderTimeout: getEnvAsInt("SERVER_READ_HEADER_TIMEOUT", 5),
+			CacheControl:      getEnv("SERVER_CACHE_CONTROL", "public, no-cache"),
+			CompressMinSize:   getEnvAsInt("SERVER_COMPRESS_MIN_SIZE", 1024),
+			CompressCacheSize: getEnvAsInt("SERVER_COMPRESS_CACHE_SIZE", 8<<20),
 		},
 		App: AppConfig{
 			Environment: getEnv("APP_ENVIRONMENT", "development"),
 			SentryDSN:   getEnv("SENTRY_DSN", ""),
 		},
+		Store: StoreConfig{
+			Backend:    getEnv("STORE_BACKEND", StoreBackendMemory),
+			SQLitePath: getEnv("STORE_SQLITE_PATH", "hema.db"),
+			DataDir:    getEnv("STORE_DATA_DIR", ""),
+		},
+		Assets: AssetsConfig{
+			Dir:            getEnv("ASSETS_DIR", "assets"),
+			ImageCacheDir:  getEnv("ASSETS_IMAGE_CACHE_DIR", filepath.Join(os.TempDir(), "hema-lessons-images")),
+			ImageCacheSize: getEnvAsInt("ASSETS_IMAGE_CACHE_SIZE", 256<<20),
+		},
 	}
 
 	if err := validate(config); err != nil {
@@ -44,6 +87,18 @@ func validate(config *Config) error {
 	if config.Server.Addr == "" {
 		return fmt.Errorf("server address is required")
 	}
+	switch config.Store.Backend {
+	case StoreBackendMemory:
+	case StoreBackendSQLite:
+		if config.Store.SQLitePath == "" {
+			return fmt.Errorf("sqlite path is required for the %s store backend", StoreBackendSQLite)
+		}
+	default:
+		return fmt.Errorf("unknown store backend %q", config.Store.Backend)
+	}
+	if config.Assets.ImageCacheSize <= 0 {
+		return fmt.Errorf("image cache size must be positive")
+	}
 	return nil
 }
 
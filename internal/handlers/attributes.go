@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/store"
+)
+
+// typedAttributes decodes an item's attributes into the struct registered for
+// its kind and encodes them again from it, so a response carries exactly the
+// fields of the kind's schema, in schema order.
+func typedAttributes(item *models.Item) error {
+	if len(item.Attributes) == 0 {
+		return nil
+	}
+	attrs, err := itemkind.Decode(item.Kind, item.Attributes)
+	if err != nil {
+		return fmt.Errorf("item %d: %w", item.ID, err)
+	}
+	raw, err := json.Marshal(attrs)
+	if err != nil {
+		return fmt.Errorf("item %d: %w", item.ID, err)
+	}
+	item.Attributes = raw
+	return nil
+}
+
+// typedItems returns a copy of items with typed attributes. items itself is
+// left untouched, so it may be shared.
+func typedItems(items []models.Item) ([]models.Item, error) {
+	if items == nil {
+		return nil, nil
+	}
+	typed := make([]models.Item, len(items))
+	for i, item := range items {
+		if err := typedAttributes(&item); err != nil {
+			return nil, err
+		}
+		typed[i] = item
+	}
+	return typed, nil
+}
+
+// typedTreeItems gives the items of freshly pruned tree nodes typed
+// attributes.
+func typedTreeItems(nodes []store.TreeNode) error {
+	for i := range nodes {
+		items, err := typedItems(nodes[i].Items)
+		if err != nil {
+			return err
+		}
+		nodes[i].Items = items
+		if err := typedTreeItems(nodes[i].Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typedConcordance gives every entry of a concordance typed attributes.
+func typedConcordance(c *store.Concordance) error {
+	for _, entries := range [][]store.ConcordanceEntry{c.Versions, c.Related} {
+		for i := range entries {
+			if err := typedAttributes(&entries[i].Item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
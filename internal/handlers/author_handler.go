@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"hema-lessons/internal/lineage"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/store"
+)
+
+type AuthorHandler struct {
+	store store.ContentRepository
+}
+
+func NewAuthorHandler(s store.ContentRepository) *AuthorHandler {
+	return &AuthorHandler{store: s}
+}
+
+// List handles GET /api/authors — returns a paginated list of authors ordered by name.
+func (h *AuthorHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := pagination.ParseParams(r)
+
+	authors, totalCount, err := h.store.ListAuthors(params)
+	if err != nil {
+		log.Printf("failed to list authors: %v", err)
+		problem.Internal(w, r, "failed to list authors")
+		return
+	}
+
+	response := pagination.NewResponse(authors, params, totalCount)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// Get handles GET /api/authors/{id} — returns a single author.
+func (h *AuthorHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "author")
+	if !ok {
+		return
+	}
+
+	author, err := h.store.GetAuthorByID(id)
+	if err != nil {
+		log.Printf("failed to get author %d: %v", id, err)
+		problem.Internal(w, r, "failed to get author")
+		return
+	}
+	if author == nil {
+		problem.NotFound(w, r, "author")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(author); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// ListResources handles GET /api/authors/{id}/resources — returns the author's works.
+func (h *AuthorHandler) ListResources(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	id, ok := pathID(w, r, "id", "author")
+	if !ok {
+		return
+	}
+
+	author, err := repo.GetAuthorByID(id)
+	if err != nil {
+		log.Printf("failed to get author %d: %v", id, err)
+		problem.Internal(w, r, "failed to list resources")
+		return
+	}
+	if author == nil {
+		problem.NotFound(w, r, "author")
+		return
+	}
+
+	resources, err := repo.ListResourcesByAuthorID(id)
+	if err != nil {
+		log.Printf("failed to list resources for author %d: %v", id, err)
+		problem.Internal(w, r, "failed to list resources")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resources); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// LineageResponse is the lineage of a single master, as the connected graph of
+// related authors and as a tree grown from that master.
+type LineageResponse struct {
+	AuthorID  int               `json:"author_id"`
+	Direction string            `json:"direction"`
+	Graph     lineage.Graph     `json:"graph"`
+	Tree      *lineage.TreeNode `json:"tree"`
+}
+
+// LineageGraph handles GET /api/lineage — returns every author and relation as a graph.
+func (h *AuthorHandler) LineageGraph(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.store.ListAllAuthors()
+	if err != nil {
+		log.Printf("failed to list authors: %v", err)
+		problem.Internal(w, r, "failed to build lineage")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(lineage.New(authors).Graph()); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// Lineage handles GET /api/authors/{id}/lineage — returns the master's lineage
+// as a graph and as a tree. ?direction=ancestors grows the tree towards the
+// master's teachers instead of their students.
+func (h *AuthorHandler) Lineage(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "author")
+	if !ok {
+		return
+	}
+
+	direction := r.URL.Query().Get("direction")
+	switch direction {
+	case "":
+		direction = lineage.Descendants
+	case lineage.Descendants, lineage.Ancestors:
+	default:
+		problem.InvalidParam(w, r, "direction", "direction must be descendants or ancestors")
+		return
+	}
+
+	authors, err := h.store.ListAllAuthors()
+	if err != nil {
+		log.Printf("failed to list authors: %v", err)
+		problem.Internal(w, r, "failed to build lineage")
+		return
+	}
+
+	l := lineage.New(authors)
+	if !l.Has(id) {
+		problem.NotFound(w, r, "author")
+		return
+	}
+
+	response := LineageResponse{
+		AuthorID:  id,
+		Direction: direction,
+		Graph:     l.Component(id),
+		Tree:      l.Tree(id, direction),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hema-lessons/internal/lineage"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/testutil"
+)
+
+func TestAuthorHandler_List(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewAuthorHandler(s)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedCount      int
+		expectedTotalPages int
+		expectedFirstName  string
+	}{
+		{
+			name:               "default pagination",
+			query:              "",
+			expectedCount:      3,
+			expectedTotalPages: 1,
+			expectedFirstName:  "Test Author 1",
+		},
+		{
+			name:               "second page of two",
+			query:              "?page=2&page_size=2",
+			expectedCount:      1,
+			expectedTotalPages: 2,
+			expectedFirstName:  "Test Author 3",
+		},
+		{
+			name:               "page beyond available data",
+			query:              "?page=5",
+			expectedCount:      0,
+			expectedTotalPages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/authors"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.List, w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var response struct {
+				pagination.Response
+				Data []models.Author `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if len(response.Data) != tt.expectedCount {
+				t.Errorf("expected %d authors, got %d", tt.expectedCount, len(response.Data))
+			}
+			if response.TotalCount != 3 {
+				t.Errorf("expected total_count 3, got %d", response.TotalCount)
+			}
+			if response.TotalPages != tt.expectedTotalPages {
+				t.Errorf("expected total_pages %d, got %d", tt.expectedTotalPages, response.TotalPages)
+			}
+			if tt.expectedCount > 0 && response.Data[0].Name != tt.expectedFirstName {
+				t.Errorf("expected first author %q, got %q", tt.expectedFirstName, response.Data[0].Name)
+			}
+		})
+	}
+}
+
+func TestAuthorHandler_Get(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewAuthorHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedName       string
+	}{
+		{
+			name:               "existing author",
+			path:               "/api/authors/2",
+			expectedStatusCode: http.StatusOK,
+			expectedName:       "Test Author 2",
+		},
+		{
+			name:               "non-existent author",
+			path:               "/api/authors/999",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid author ID",
+			path:               "/api/authors/abc",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Get, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var author models.Author
+			if err := json.NewDecoder(w.Body).Decode(&author); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if author.Name != tt.expectedName {
+				t.Errorf("expected name %q, got %q", tt.expectedName, author.Name)
+			}
+			if author.BirthYear == nil || author.DeathYear == nil {
+				t.Error("expected birth and death years to be set")
+			}
+		})
+	}
+}
+
+func TestAuthorHandler_ListResources(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewAuthorHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedTitles     []string
+	}{
+		{
+			name:               "author with two works",
+			path:               "/api/authors/1/resources",
+			expectedStatusCode: http.StatusOK,
+			expectedTitles:     []string{"Book A", "Book B"},
+		},
+		{
+			name:               "author with one work",
+			path:               "/api/authors/3/resources",
+			expectedStatusCode: http.StatusOK,
+			expectedTitles:     []string{"Book E"},
+		},
+		{
+			name:               "non-existent author",
+			path:               "/api/authors/999/resources",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid author ID",
+			path:               "/api/authors/0/resources",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.ListResources, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var resources []store.ResourceWithAuthor
+			if err := json.NewDecoder(w.Body).Decode(&resources); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resources) != len(tt.expectedTitles) {
+				t.Fatalf("expected %d resources, got %d", len(tt.expectedTitles), len(resources))
+			}
+			for i, title := range tt.expectedTitles {
+				if resources[i].Title != title {
+					t.Errorf("expected resource %d to be %q, got %q", i, title, resources[i].Title)
+				}
+				if resources[i].AuthorName == "" {
+					t.Error("expected AuthorName to be set")
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorHandler_Lineage(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewAuthorHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedDirection  string
+		expectedChildren   []int
+	}{
+		{
+			name:               "descendants by default",
+			path:               "/api/authors/1/lineage",
+			expectedStatusCode: http.StatusOK,
+			expectedDirection:  "descendants",
+			expectedChildren:   []int{2, 3},
+		},
+		{
+			name:               "ancestors",
+			path:               "/api/authors/2/lineage?direction=ancestors",
+			expectedStatusCode: http.StatusOK,
+			expectedDirection:  "ancestors",
+			expectedChildren:   []int{1},
+		},
+		{
+			name:               "invalid direction",
+			path:               "/api/authors/1/lineage?direction=sideways",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "non-existent author",
+			path:               "/api/authors/999/lineage",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid author ID",
+			path:               "/api/authors/abc/lineage",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Lineage, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response LineageResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Direction != tt.expectedDirection {
+				t.Errorf("expected direction %q, got %q", tt.expectedDirection, response.Direction)
+			}
+			if len(response.Graph.Nodes) != 3 || len(response.Graph.Edges) != 3 {
+				t.Errorf("expected 3 nodes and 3 edges, got %d and %d", len(response.Graph.Nodes), len(response.Graph.Edges))
+			}
+			if response.Tree == nil {
+				t.Fatal("expected a tree")
+			}
+			var children []int
+			for _, c := range response.Tree.Children {
+				children = append(children, c.ID)
+			}
+			if len(children) != len(tt.expectedChildren) {
+				t.Fatalf("expected children %v, got %v", tt.expectedChildren, children)
+			}
+			for i, id := range tt.expectedChildren {
+				if children[i] != id {
+					t.Errorf("expected children %v, got %v", tt.expectedChildren, children)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorHandler_LineageGraph(t *testing.T) {
+	handler := NewAuthorHandler(testutil.NewTestStore())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lineage", nil)
+	w := httptest.NewRecorder()
+	serve(handler.LineageGraph, w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var graph lineage.Graph
+	if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(graph.Nodes) != 3 {
+		t.Errorf("expected 3 nodes, got %d", len(graph.Nodes))
+	}
+	if len(graph.Edges) != 3 {
+		t.Errorf("expected 3 edges, got %d", len(graph.Edges))
+	}
+}
@@ -7,52 +7,322 @@ import (
 	"strconv"
 	"strings"
 
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/problem"
 	"hema-lessons/internal/store"
+	"hema-lessons/internal/techgraph"
+)
+
+const (
+	defaultGraphDepth = 2
+	maxGraphDepth     = 5
+)
+
+// Technique graph output formats, selected with ?format=.
+const (
+	graphFormatJSON = "json"
+	graphFormatDOT  = "dot"
 )
 
 type ItemHandler struct {
-	store *store.Store
+	store store.ContentRepository
 }
 
-func NewItemHandler(s *store.Store) *ItemHandler {
+func NewItemHandler(s store.ContentRepository) *ItemHandler {
 	return &ItemHandler{store: s}
 }
 
-// ListBySection handles GET /api/sections/:id/items — returns items within a section.
+// ListBySection handles GET /api/sections/{id}/items — returns items within a
+// section, optionally only those of one ?kind=.
 func (h *ItemHandler) ListBySection(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	repo := h.store.View()
 
-	prefix := "/api/sections/"
-	if !strings.HasPrefix(path, prefix) {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	sectionID, ok := pathID(w, r, "id", "section")
+	if !ok {
 		return
 	}
 
-	remaining := path[len(prefix):]
-	parts := strings.Split(remaining, "/")
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && !itemkind.Known(kind) {
+		problem.InvalidParam(w, r, "kind", "unknown kind, expected one of "+strings.Join(itemkind.Kinds(), ", "))
+		return
+	}
 
-	if len(parts) != 2 || parts[1] != "items" {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	section, err := repo.GetSectionByID(sectionID)
+	if err != nil {
+		log.Printf("failed to get section %d: %v", sectionID, err)
+		problem.Internal(w, r, "failed to list items")
+		return
+	}
+	if section == nil {
+		problem.NotFound(w, r, "section")
 		return
 	}
 
-	sectionID, err := strconv.Atoi(parts[0])
-	if err != nil || sectionID <= 0 {
-		http.Error(w, "invalid section ID", http.StatusBadRequest)
+	items, err := repo.ListItemsBySectionID(sectionID)
+	if err != nil {
+		log.Printf("failed to list items for section %d: %v", sectionID, err)
+		problem.Internal(w, r, "failed to list items")
+		return
+	}
+	if kind != "" {
+		items = filterItemsByKind(items, kind)
+	}
+	if items, err = typedItems(items); err != nil {
+		log.Printf("failed to decode attributes in section %d: %v", sectionID, err)
+		problem.Internal(w, r, "failed to list items")
 		return
 	}
 
-	if h.store.GetSectionByID(sectionID) == nil {
-		http.Error(w, "section not found", http.StatusNotFound)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// List handles GET /api/items — returns paginated items filtered by
+// ?weapon=, ?guard=, ?action= and ?armour=, each with its effective tags.
+// Values may be repeated or comma-separated; any listed term of a facet
+// matches, and every given facet must match.
+func (h *ItemHandler) List(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	summary, err := repo.TagSummary()
+	if err != nil {
+		log.Printf("failed to list tags: %v", err)
+		problem.Internal(w, r, "failed to list items")
+		return
+	}
+
+	filter, ok := parseTagFilter(w, r, summary)
+	if !ok {
 		return
 	}
 
-	items := h.store.ListItemsBySectionID(sectionID)
+	params := pagination.ParseParams(r)
+
+	items, totalCount, err := repo.ListItemsByTags(filter, params)
+	if err != nil {
+		log.Printf("failed to list items by tags: %v", err)
+		problem.Internal(w, r, "failed to list items")
+		return
+	}
+	if items == nil {
+		items = []store.TaggedItem{}
+	}
+	for i := range items {
+		if err := typedAttributes(&items[i].Item); err != nil {
+			log.Printf("failed to decode attributes: %v", err)
+			problem.Internal(w, r, "failed to list items")
+			return
+		}
+	}
+
+	response := pagination.NewResponse(items, params, totalCount)
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(items); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// Get handles GET /api/items/{id} and GET /api/items/{resource}/{slug} — returns
+// an item with its section, resource, breadcrumb and navigation links.
+func (h *ItemHandler) Get(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	id, ok := resolveItemID(w, r, repo)
+	if !ok {
+		return
+	}
+
+	detail, err := store.GetItemDetail(repo, id)
+	if err != nil {
+		log.Printf("failed to get item %d: %v", id, err)
+		problem.Internal(w, r, "failed to get item")
+		return
+	}
+	if detail == nil {
+		problem.NotFound(w, r, "item")
+		return
+	}
+
+	detail.Navigation, err = store.ItemNavigation(repo, &detail.Item)
+	if err != nil {
+		log.Printf("failed to get navigation for item %d: %v", id, err)
+		problem.Internal(w, r, "failed to get item")
+		return
+	}
+	if err := typedAttributes(&detail.Item); err != nil {
+		log.Printf("failed to decode attributes: %v", err)
+		problem.Internal(w, r, "failed to get item")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
 
+// resolveItemID reads an item ID from /api/items/{id}, or looks the item up
+// by slug from /api/items/{resource}/{slug}, where {resource} is a resource ID
+// or slug. Former slugs are redirected. Returns false if a response has been
+// written.
+func resolveItemID(w http.ResponseWriter, r *http.Request, repo store.ContentRepository) (int, bool) {
+	slug := r.PathValue("slug")
+	if slug == "" {
+		return pathID(w, r, "id", "item")
+	}
+
+	resource := r.PathValue("resource")
+	resourceID, ok := resolveResourceID(w, r, repo, "/api/items/", resource)
+	if !ok {
+		return 0, false
+	}
+	if !isSlug(slug) {
+		problem.InvalidParam(w, r, "slug", "invalid item slug")
+		return 0, false
+	}
+
+	item, err := repo.GetItemBySlug(resourceID, slug)
+	if err != nil {
+		log.Printf("failed to look up item %q of resource %d: %v", slug, resourceID, err)
+		problem.Internal(w, r, "failed to get item")
+		return 0, false
+	}
+	if item == nil {
+		problem.NotFound(w, r, "item")
+		return 0, false
+	}
+	if item.Slug != slug {
+		redirectToSlug(w, r, "/api/items/"+resource+"/", slug, item.Slug)
+		return 0, false
+	}
+	return item.ID, true
+}
+
+// Concordance handles GET /api/items/{id}/concordance — returns every version
+// of the item's technique across treatises side by side, plus the techniques
+// linked to it as counters or prerequisites.
+func (h *ItemHandler) Concordance(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	id, ok := pathID(w, r, "id", "item")
+	if !ok {
+		return
+	}
+
+	result, err := store.GetConcordance(repo, id)
+	if err != nil {
+		log.Printf("failed to get concordance for item %d: %v", id, err)
+		problem.Internal(w, r, "failed to get concordance")
+		return
+	}
+	if result == nil {
+		problem.NotFound(w, r, "item")
+		return
+	}
+	if err := typedConcordance(result); err != nil {
+		log.Printf("failed to decode attributes: %v", err)
+		problem.Internal(w, r, "failed to get concordance")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// ItemGraphResponse is the technique graph around an item.
+type ItemGraphResponse struct {
+	ItemID int `json:"item_id"`
+	Depth  int `json:"depth"`
+	techgraph.Graph
+}
+
+// Graph handles GET /api/items/{id}/graph — returns the items within ?depth=
+// (default 2, max 5) counter, follow-up or remedy relations of the item, in
+// either direction, as JSON or, with ?format=dot, as a Graphviz digraph.
+func (h *ItemHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "item")
+	if !ok {
+		return
+	}
+
+	depth := defaultGraphDepth
+	if v := r.URL.Query().Get("depth"); v != "" {
+		var err error
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth < 0 || depth > maxGraphDepth {
+			problem.InvalidParam(w, r, "depth", "depth must be an integer from 0 to "+strconv.Itoa(maxGraphDepth))
+			return
+		}
+	}
+
+	format, ok := parseGraphFormat(w, r)
+	if !ok {
+		return
+	}
+
+	graph, err := h.store.ItemGraph(id, depth)
+	if err != nil {
+		log.Printf("failed to get graph for item %d: %v", id, err)
+		problem.Internal(w, r, "failed to get graph")
+		return
+	}
+	if graph == nil {
+		problem.NotFound(w, r, "item")
+		return
+	}
+
+	if format == graphFormatDOT {
+		writeGraphDOT(w, *graph, "item "+strconv.Itoa(id))
+		return
+	}
+
+	response := ItemGraphResponse{ItemID: id, Depth: depth, Graph: *graph}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// parseGraphFormat reads ?format=, which is json (the default) or dot. It
+// writes a 400 response and returns false for any other value.
+func parseGraphFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", graphFormatJSON:
+		return graphFormatJSON, true
+	case graphFormatDOT:
+		return graphFormatDOT, true
+	}
+	problem.InvalidParam(w, r, "format", "format must be json or dot")
+	return "", false
+}
+
+func writeGraphDOT(w http.ResponseWriter, graph techgraph.Graph, name string) {
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	if err := techgraph.WriteDOT(w, graph, name); err != nil {
+		log.Printf("failed to write DOT graph: %v", err)
+	}
+}
+
+func filterItemsByKind(items []models.Item, kind string) []models.Item {
+	var filtered []models.Item
+	for _, item := range items {
+		if item.Kind == kind {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"hema-lessons/internal/models"
+	"hema-lessons/internal/store"
 	"hema-lessons/internal/testutil"
 )
 
@@ -65,7 +69,7 @@ func TestItemHandler_ListBySection(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
 			w := httptest.NewRecorder()
 
-			handler.ListBySection(w, req)
+			serve(handler.ListBySection, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -91,7 +95,7 @@ func TestItemHandler_ListBySection_VerifyOrdering(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/sections/1/items", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListBySection(w, req)
+	serve(handler.ListBySection, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -117,7 +121,7 @@ func TestItemHandler_ListBySection_VerifyFields(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/sections/1/items", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListBySection(w, req)
+	serve(handler.ListBySection, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -145,6 +149,60 @@ func TestItemHandler_ListBySection_VerifyFields(t *testing.T) {
 	}
 }
 
+func TestItemHandler_TypedAttributes(t *testing.T) {
+	items := testutil.TestItems()
+	items[0].Attributes = json.RawMessage(`{ "historical_image_url": "/assets/plate.jpg",
+		"instructions": "Seize the elbow" }`)
+	s := store.NewFromData(testutil.TestAuthors(), testutil.TestResources(), testutil.TestSections(), items,
+		testutil.TestConcordance(), testutil.TestTaxonomy())
+	handler := NewItemHandler(s)
+
+	// Attributes are encoded from the kind's struct, in schema order.
+	expected := `{"instructions":"Seize the elbow","historical_image_url":"/assets/plate.jpg"}`
+	for _, tt := range []struct {
+		path    string
+		handler http.HandlerFunc
+		decode  func(body []byte) (json.RawMessage, error)
+	}{
+		{
+			path:    "/api/items/1",
+			handler: handler.Get,
+			decode: func(body []byte) (json.RawMessage, error) {
+				var item models.Item
+				err := json.Unmarshal(body, &item)
+				return item.Attributes, err
+			},
+		},
+		{
+			path:    "/api/sections/1/items",
+			handler: handler.ListBySection,
+			decode: func(body []byte) (json.RawMessage, error) {
+				var items []models.Item
+				err := json.Unmarshal(body, &items)
+				if len(items) == 0 {
+					return nil, err
+				}
+				return items[0].Attributes, err
+			},
+		},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			serve(tt.handler, w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			attrs, err := tt.decode(w.Body.Bytes())
+			if err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if string(attrs) != expected {
+				t.Errorf("expected attributes %s, got %s", expected, attrs)
+			}
+		})
+	}
+}
+
 func TestItemHandler_ListBySection_EmptyResult(t *testing.T) {
 	s := testutil.NewStoreWithAuthorsResourcesSections()
 	handler := NewItemHandler(s)
@@ -152,7 +210,7 @@ func TestItemHandler_ListBySection_EmptyResult(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/sections/1/items", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListBySection(w, req)
+	serve(handler.ListBySection, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -167,3 +225,447 @@ func TestItemHandler_ListBySection_EmptyResult(t *testing.T) {
 		t.Errorf("expected empty result, got %d items", len(items))
 	}
 }
+
+func TestItemHandler_Get(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedTitle      string
+		expectedSection    int
+		expectedResource   string
+		expectedAuthor     string
+		expectedBreadcrumb []string
+	}{
+		{
+			name:               "item in root section",
+			path:               "/api/items/1",
+			expectedStatusCode: http.StatusOK,
+			expectedTitle:      "Technique 1",
+			expectedSection:    1,
+			expectedResource:   "Book A",
+			expectedAuthor:     "Test Author 1",
+			expectedBreadcrumb: []string{"resource:Book A", "section:Chapter 1"},
+		},
+		{
+			name:               "item in nested section",
+			path:               "/api/items/6",
+			expectedStatusCode: http.StatusOK,
+			expectedTitle:      "Nested Technique",
+			expectedSection:    6,
+			expectedResource:   "Book A",
+			expectedAuthor:     "Test Author 1",
+			expectedBreadcrumb: []string{"resource:Book A", "section:Chapter 1", "section:Sub-section of Chapter 1"},
+		},
+		{
+			name:               "non-existent item",
+			path:               "/api/items/999",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid item ID - string",
+			path:               "/api/items/abc",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid item ID - zero",
+			path:               "/api/items/0",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Get, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var detail store.ItemDetail
+			if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if detail.Title != tt.expectedTitle {
+				t.Errorf("expected title %q, got %q", tt.expectedTitle, detail.Title)
+			}
+			if detail.Section.ID != tt.expectedSection {
+				t.Errorf("expected section %d, got %d", tt.expectedSection, detail.Section.ID)
+			}
+			if detail.Resource.Title != tt.expectedResource {
+				t.Errorf("expected resource %q, got %q", tt.expectedResource, detail.Resource.Title)
+			}
+			if detail.Resource.AuthorName != tt.expectedAuthor {
+				t.Errorf("expected author %q, got %q", tt.expectedAuthor, detail.Resource.AuthorName)
+			}
+
+			var breadcrumb []string
+			for _, c := range detail.Breadcrumb {
+				breadcrumb = append(breadcrumb, c.Type+":"+c.Title)
+			}
+			if strings.Join(breadcrumb, " > ") != strings.Join(tt.expectedBreadcrumb, " > ") {
+				t.Errorf("expected breadcrumb %v, got %v", tt.expectedBreadcrumb, breadcrumb)
+			}
+		})
+	}
+}
+
+func TestItemHandler_ListBySection_FilterByKind(t *testing.T) {
+	items := append(testutil.TestItems(),
+		models.Item{ID: 7, SectionID: 3, Kind: "drill", Title: "Cut Drill", Position: 1,
+			Attributes: json.RawMessage(`{"instructions":"Cut","reps":10,"roles":[{"role":"agent","instructions":"Strike"}]}`)},
+		models.Item{ID: 8, SectionID: 3, Kind: "quote", Title: "Verse", Position: 2,
+			Attributes: json.RawMessage(`{"lines":["Jung ritter lere"],"language":"de"}`)},
+		models.Item{ID: 9, SectionID: 3, Kind: "plate", Title: "Plate", Position: 3,
+			Attributes: json.RawMessage(`{"image_url":"/assets/books/book-a/plates/1r.jpg","folio":"1r"}`)},
+		models.Item{ID: 10, SectionID: 3, Kind: "video", Title: "Lesson", Position: 4,
+			Attributes: json.RawMessage(`{"video_url":"https://example.com/v.mp4","duration_seconds":300}`)},
+		models.Item{ID: 11, SectionID: 3, Kind: "drill", Title: "Thrust Drill", Position: 5,
+			Attributes: json.RawMessage(`{"instructions":"Thrust","reps":5}`)},
+	)
+	s := store.NewFromData(testutil.TestAuthors(), testutil.TestResources(), testutil.TestSections(), items, testutil.TestConcordance(), testutil.TestTaxonomy())
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedIDs        []int
+	}{
+		{name: "no filter", query: "", expectedStatusCode: http.StatusOK, expectedIDs: []int{7, 8, 9, 10, 11}},
+		{name: "drills", query: "?kind=drill", expectedStatusCode: http.StatusOK, expectedIDs: []int{7, 11}},
+		{name: "videos", query: "?kind=video", expectedStatusCode: http.StatusOK, expectedIDs: []int{10}},
+		{name: "no items of kind", query: "?kind=technique", expectedStatusCode: http.StatusOK},
+		{name: "unknown kind", query: "?kind=kata", expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/sections/3/items"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.ListBySection, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var got []models.Item
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(got) != len(tt.expectedIDs) {
+				t.Fatalf("expected %d items, got %d", len(tt.expectedIDs), len(got))
+			}
+			for i, id := range tt.expectedIDs {
+				if got[i].ID != id {
+					t.Errorf("expected item %d at position %d, got %d", id, i, got[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestItemHandler_Concordance(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedVersions   []string
+		expectedRelated    []string
+	}{
+		{
+			name:               "versions and incoming counter",
+			path:               "/api/items/4/concordance",
+			expectedStatusCode: http.StatusOK,
+			expectedVersions:   []string{"4:", "1:same", "2:variant"},
+			expectedRelated:    []string{"3:counter:incoming"},
+		},
+		{
+			name:               "variant reached through a same link",
+			path:               "/api/items/1/concordance",
+			expectedStatusCode: http.StatusOK,
+			expectedVersions:   []string{"1:", "2:variant", "4:same"},
+			expectedRelated:    []string{"5:prerequisite:incoming"},
+		},
+		{
+			name:               "item without links",
+			path:               "/api/items/6/concordance",
+			expectedStatusCode: http.StatusOK,
+			expectedVersions:   []string{"6:"},
+			expectedRelated:    []string{},
+		},
+		{
+			name:               "non-existent item",
+			path:               "/api/items/999/concordance",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid item ID",
+			path:               "/api/items/abc/concordance",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Concordance, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var result store.Concordance
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			var versions []string
+			for _, v := range result.Versions {
+				versions = append(versions, fmt.Sprintf("%d:%s", v.ID, v.Relation))
+				if v.Resource.Title == "" || len(v.Breadcrumb) == 0 {
+					t.Errorf("expected version %d to carry its resource and breadcrumb", v.ID)
+				}
+			}
+			if !reflect.DeepEqual(versions, tt.expectedVersions) {
+				t.Errorf("expected versions %v, got %v", tt.expectedVersions, versions)
+			}
+
+			related := []string{}
+			for _, r := range result.Related {
+				related = append(related, fmt.Sprintf("%d:%s:%s", r.ID, r.Relation, r.Direction))
+			}
+			if !reflect.DeepEqual(related, tt.expectedRelated) {
+				t.Errorf("expected related %v, got %v", tt.expectedRelated, related)
+			}
+		})
+	}
+}
+
+func TestItemHandler_Concordance_BackendError(t *testing.T) {
+	handler := NewItemHandler(testutil.FailingRepository{})
+
+	w := httptest.NewRecorder()
+	serve(handler.Concordance, w, httptest.NewRequest(http.MethodGet, "/api/items/1/concordance", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestItemHandler_Graph(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedDepth      int
+		expectedNodes      []int
+		expectedEdges      []string
+	}{
+		{
+			name:               "default depth",
+			path:               "/api/items/1/graph",
+			expectedStatusCode: http.StatusOK,
+			expectedDepth:      2,
+			expectedNodes:      []int{1, 2, 3, 4, 5},
+			expectedEdges:      []string{"1-follow_up->2", "2-follow_up->3", "4-counters->1", "5-is_remedy_of->4"},
+		},
+		{
+			name:               "depth 1",
+			path:               "/api/items/1/graph?depth=1",
+			expectedStatusCode: http.StatusOK,
+			expectedDepth:      1,
+			expectedNodes:      []int{1, 2, 4},
+			expectedEdges:      []string{"1-follow_up->2", "4-counters->1"},
+		},
+		{
+			name:               "item without relations",
+			path:               "/api/items/6/graph",
+			expectedStatusCode: http.StatusOK,
+			expectedDepth:      2,
+			expectedNodes:      []int{6},
+			expectedEdges:      []string{},
+		},
+		{
+			name:               "depth too large",
+			path:               "/api/items/1/graph?depth=6",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown format",
+			path:               "/api/items/1/graph?format=svg",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "non-existent item",
+			path:               "/api/items/999/graph",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid item ID",
+			path:               "/api/items/abc/graph",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Graph, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response ItemGraphResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Depth != tt.expectedDepth {
+				t.Errorf("expected depth %d, got %d", tt.expectedDepth, response.Depth)
+			}
+
+			var nodes []int
+			for _, n := range response.Nodes {
+				nodes = append(nodes, n.ID)
+			}
+			if !reflect.DeepEqual(nodes, tt.expectedNodes) {
+				t.Errorf("expected nodes %v, got %v", tt.expectedNodes, nodes)
+			}
+
+			edges := []string{}
+			for _, e := range response.Edges {
+				edges = append(edges, fmt.Sprintf("%d-%s->%d", e.From, e.Type, e.To))
+			}
+			if !reflect.DeepEqual(edges, tt.expectedEdges) {
+				t.Errorf("expected edges %v, got %v", tt.expectedEdges, edges)
+			}
+		})
+	}
+}
+
+func TestItemHandler_Graph_DOT(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items/2/graph?depth=1&format=dot", nil)
+	w := httptest.NewRecorder()
+
+	serve(handler.Graph, w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/vnd.graphviz") {
+		t.Errorf("expected a Graphviz content type, got %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`digraph "item 2" {`,
+		`2 [label="Technique 2"];`,
+		`1 -> 2 [label="follow_up", color="black", tooltip="Continue into the second play"];`,
+		`2 -> 3 [label="follow_up", color="black"];`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected DOT output to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestItemHandler_Get_Navigation(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name             string
+		id               int
+		expectedPrevious string
+		expectedNext     string
+		expectedNextItem string
+	}{
+		{
+			name:             "first item of a section",
+			id:               1,
+			expectedNext:     "item 2",
+			expectedNextItem: "item 2",
+		},
+		{
+			name:             "last item continues into the sub-section",
+			id:               3,
+			expectedPrevious: "item 2",
+			expectedNextItem: "item 6",
+		},
+		{
+			name:             "sub-section continues into the next chapter",
+			id:               6,
+			expectedNextItem: "item 4",
+		},
+		{
+			name:             "last item of the resource",
+			id:               5,
+			expectedPrevious: "item 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/items/%d", tt.id), nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Get, w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var item store.ItemDetail
+			if err := json.NewDecoder(w.Body).Decode(&item); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if item.Navigation == nil {
+				t.Fatal("expected navigation to be set")
+			}
+
+			if got := crumbRef(item.Navigation.Previous); got != tt.expectedPrevious {
+				t.Errorf("expected previous %q, got %q", tt.expectedPrevious, got)
+			}
+			if got := crumbRef(item.Navigation.Next); got != tt.expectedNext {
+				t.Errorf("expected next %q, got %q", tt.expectedNext, got)
+			}
+			if got := crumbRef(item.Navigation.NextItem); got != tt.expectedNextItem {
+				t.Errorf("expected next item %q, got %q", tt.expectedNextItem, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/testutil"
+)
+
+// TestSQLiteBackend_MatchesMemoryStore checks that every endpoint returns the
+// same body whether it is served from the embedded store or from SQLite.
+func TestSQLiteBackend_MatchesMemoryStore(t *testing.T) {
+	memory := testutil.NewTestStore()
+	sqlite := testutil.NewTestSQLiteStore(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		handler func(repo store.ContentRepository) http.HandlerFunc
+	}{
+		{
+			name:    "list authors",
+			path:    "/api/authors?page=1&page_size=2",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewAuthorHandler(repo).List },
+		},
+		{
+			name:    "get author",
+			path:    "/api/authors/2",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewAuthorHandler(repo).Get },
+		},
+		{
+			name:    "list author resources",
+			path:    "/api/authors/1/resources",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewAuthorHandler(repo).ListResources },
+		},
+		{
+			name:    "author lineage",
+			path:    "/api/authors/2/lineage?direction=ancestors",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewAuthorHandler(repo).Lineage },
+		},
+		{
+			name:    "lineage graph",
+			path:    "/api/lineage",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewAuthorHandler(repo).LineageGraph },
+		},
+		{
+			name:    "list resources",
+			path:    "/api/resources?page=1&page_size=3",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewResourceHandler(repo).List },
+		},
+		{
+			name:    "list resources beyond last page",
+			path:    "/api/resources?page=9",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewResourceHandler(repo).List },
+		},
+		{
+			name:    "get resource",
+			path:    "/api/resources/3",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewResourceHandler(repo).Get },
+		},
+		{
+			name:    "get missing resource",
+			path:    "/api/resources/999",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewResourceHandler(repo).Get },
+		},
+		{
+			name:    "list root sections",
+			path:    "/api/resources/1/sections",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).ListByBook },
+		},
+		{
+			name:    "get nested section",
+			path:    "/api/sections/6",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).Get },
+		},
+		{
+			name:    "list child sections",
+			path:    "/api/sections/1/sections",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).ListChildren },
+		},
+		{
+			name:    "list items",
+			path:    "/api/sections/1/items",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).ListBySection },
+		},
+		{
+			name:    "get item",
+			path:    "/api/items/6",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).Get },
+		},
+		{
+			name:    "item by slug",
+			path:    "/api/items/book-a/technique-2",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).Get },
+		},
+		{
+			name:    "section by former slug",
+			path:    "/api/sections/book-a/part-two",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).Get },
+		},
+		{
+			name:    "section with navigation",
+			path:    "/api/sections/6",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).Get },
+		},
+		{
+			name:    "resource tree",
+			path:    "/api/resources/1/tree?include=items",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewResourceHandler(repo).Tree },
+		},
+		{
+			name:    "item graph",
+			path:    "/api/items/1/graph",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).Graph },
+		},
+		{
+			name:    "section graph",
+			path:    "/api/sections/1/graph?format=dot",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSectionHandler(repo).Graph },
+		},
+		{
+			name:    "item concordance",
+			path:    "/api/items/4/concordance",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).Concordance },
+		},
+		{
+			name:    "search",
+			path:    "/api/search?q=technique&page_size=3",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSearchHandler(repo).Search },
+		},
+		{
+			name:    "suggest",
+			path:    "/api/suggest?prefix=t",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewSuggestHandler(repo).Suggest },
+		},
+		{
+			name:    "tags",
+			path:    "/api/tags",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewTagHandler(repo).List },
+		},
+		{
+			name:    "items by tags",
+			path:    "/api/items?armour=unarmoured&action=lock,thrust",
+			handler: func(repo store.ContentRepository) http.HandlerFunc { return NewItemHandler(repo).List },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := httptest.NewRecorder()
+			serve(tt.handler(memory), want, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			got := httptest.NewRecorder()
+			serve(tt.handler(sqlite), got, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got.Code != want.Code {
+				t.Errorf("expected status code %d, got %d", want.Code, got.Code)
+			}
+			if got.Body.String() != want.Body.String() {
+				t.Errorf("response bodies differ\nmemory: %s\nsqlite: %s", want.Body.String(), got.Body.String())
+			}
+		})
+	}
+}
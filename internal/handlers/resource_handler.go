@@ -8,61 +8,119 @@ import (
 	"strings"
 
 	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/problem"
 	"hema-lessons/internal/store"
 )
 
 type ResourceHandler struct {
-	store *store.Store
+	store store.ContentRepository
 }
 
-func NewResourceHandler(s *store.Store) *ResourceHandler {
+func NewResourceHandler(s store.ContentRepository) *ResourceHandler {
 	return &ResourceHandler{store: s}
 }
 
 func (h *ResourceHandler) List(w http.ResponseWriter, r *http.Request) {
 	params := pagination.ParseParams(r)
 
-	resources, totalCount := h.store.ListResources(params)
+	resources, totalCount, err := h.store.ListResources(params)
+	if err != nil {
+		log.Printf("failed to list resources: %v", err)
+		problem.Internal(w, r, "failed to list resources")
+		return
+	}
 
 	response := pagination.NewResponse(resources, params, totalCount)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
 
 func (h *ResourceHandler) Get(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	repo := h.store.View()
+
+	id, ok := resolveResourceID(w, r, repo, "/api/resources/", r.PathValue("resource"))
+	if !ok {
+		return
+	}
 
-	prefix := "/api/resources/"
-	if !strings.HasPrefix(path, prefix) {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	resource, err := repo.GetResourceByID(id)
+	if err != nil {
+		log.Printf("failed to get resource %d: %v", id, err)
+		problem.Internal(w, r, "failed to get resource")
+		return
+	}
+	if resource == nil {
+		problem.NotFound(w, r, "resource")
 		return
 	}
 
-	idStr := path[len(prefix):]
-	if idStr == "" {
-		http.Error(w, "invalid resource ID", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resource); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// includeItems is the ?include= value that adds items to a resource tree.
+const includeItems = "items"
+
+// Tree handles GET /api/resources/{resource}/tree — returns the resource's nested
+// sections in one response, down to ?depth= levels (every level by default),
+// with each section's items when ?include=items is given.
+func (h *ResourceHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	id, ok := resolveResourceID(w, r, repo, "/api/resources/", r.PathValue("resource"))
+	if !ok {
 		return
 	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id <= 0 {
-		http.Error(w, "invalid resource ID", http.StatusBadRequest)
+	depth := 0
+	if v := r.URL.Query().Get("depth"); v != "" {
+		var err error
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth <= 0 {
+			problem.InvalidParam(w, r, "depth", "depth must be a positive integer")
+			return
+		}
+	}
+
+	withItems := false
+	if v := r.URL.Query().Get("include"); v != "" {
+		for _, inc := range strings.Split(v, ",") {
+			if strings.TrimSpace(inc) != includeItems {
+				problem.InvalidParam(w, r, "include", "include must be items")
+				return
+			}
+			withItems = true
+		}
+	}
+
+	tree, err := repo.ResourceTree(id)
+	if err != nil {
+		log.Printf("failed to get tree for resource %d: %v", id, err)
+		problem.Internal(w, r, "failed to get resource tree")
+		return
+	}
+	if tree == nil {
+		problem.NotFound(w, r, "resource")
 		return
 	}
 
-	resource := h.store.GetResourceByID(id)
-	if resource == nil {
-		http.Error(w, "resource not found", http.StatusNotFound)
+	pruned := tree.Prune(depth, withItems)
+	if err := typedTreeItems(pruned.Sections); err != nil {
+		log.Printf("failed to decode attributes in resource %d: %v", id, err)
+		problem.Internal(w, r, "failed to get resource tree")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resource); err != nil {
+	if err := json.NewEncoder(w).Encode(pruned); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
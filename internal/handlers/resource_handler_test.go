@@ -93,7 +93,7 @@ func TestResourceHandler_List(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/api/resources"+tt.queryParams, nil)
 			w := httptest.NewRecorder()
 
-			handler.List(w, req)
+			serve(handler.List, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -153,7 +153,7 @@ func TestResourceHandler_List_EmptyDatabase(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources", nil)
 	w := httptest.NewRecorder()
 
-	handler.List(w, req)
+	serve(handler.List, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -179,7 +179,7 @@ func TestResourceHandler_List_VerifyOrdering(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources", nil)
 	w := httptest.NewRecorder()
 
-	handler.List(w, req)
+	serve(handler.List, w, req)
 
 	var response pagination.Response
 	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
@@ -247,7 +247,7 @@ func TestResourceHandler_Get(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, url, nil)
 			w := httptest.NewRecorder()
 
-			handler.Get(w, req)
+			serve(handler.Get, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -274,3 +274,146 @@ func TestResourceHandler_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceHandler_RepositoryError(t *testing.T) {
+	handler := NewResourceHandler(testutil.FailingRepository{})
+
+	tests := []struct {
+		name    string
+		path    string
+		handler http.HandlerFunc
+	}{
+		{name: "list", path: "/api/resources", handler: handler.List},
+		{name: "get", path: "/api/resources/1", handler: handler.Get},
+		{name: "tree", path: "/api/resources/1/tree", handler: handler.Tree},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(tt.handler, w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+			}
+		})
+	}
+}
+
+func TestResourceHandler_Tree(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewResourceHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedOutline    []string
+	}{
+		{
+			name:               "every level without items",
+			path:               "/api/resources/1/tree",
+			expectedStatusCode: http.StatusOK,
+			expectedOutline:    []string{"section 1", "  section 6", "section 2", "section 3"},
+		},
+		{
+			name:               "every level with items",
+			path:               "/api/resources/1/tree?include=items",
+			expectedStatusCode: http.StatusOK,
+			expectedOutline: []string{
+				"section 1", "  item 1", "  item 2", "  item 3",
+				"  section 6", "    item 6",
+				"section 2", "  item 4", "  item 5",
+				"section 3",
+			},
+		},
+		{
+			name:               "root sections only",
+			path:               "/api/resources/1/tree?depth=1&include=items",
+			expectedStatusCode: http.StatusOK,
+			expectedOutline: []string{
+				"section 1", "  item 1", "  item 2", "  item 3",
+				"section 2", "  item 4", "  item 5",
+				"section 3",
+			},
+		},
+		{
+			name:               "resource without sections",
+			path:               "/api/resources/5/tree",
+			expectedStatusCode: http.StatusOK,
+			expectedOutline:    []string{},
+		},
+		{
+			name:               "non-existent resource",
+			path:               "/api/resources/999/tree",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid resource ID",
+			path:               "/api/resources/0/tree",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "zero depth",
+			path:               "/api/resources/1/tree?depth=0",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "non-numeric depth",
+			path:               "/api/resources/1/tree?depth=all",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown include",
+			path:               "/api/resources/1/tree?include=authors",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Tree, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var tree store.ResourceTree
+			if err := json.NewDecoder(w.Body).Decode(&tree); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if tree.Sections == nil {
+				t.Fatal("expected sections to be a JSON array")
+			}
+			if tree.Title == "" {
+				t.Error("expected the resource fields to be set")
+			}
+
+			outline := treeOutline(tree.Sections, "")
+			if fmt.Sprint(outline) != fmt.Sprint(tt.expectedOutline) {
+				t.Errorf("expected outline %q, got %q", tt.expectedOutline, outline)
+			}
+		})
+	}
+}
+
+// treeOutline flattens tree nodes into indented "section N" and "item N" lines.
+func treeOutline(nodes []store.TreeNode, indent string) []string {
+	outline := []string{}
+	for _, n := range nodes {
+		outline = append(outline, fmt.Sprintf("%ssection %d", indent, n.ID))
+		for _, item := range n.Items {
+			outline = append(outline, fmt.Sprintf("%s  item %d", indent, item.ID))
+		}
+		outline = append(outline, treeOutline(n.Children, indent+"  ")...)
+	}
+	return outline
+}
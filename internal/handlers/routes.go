@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/router"
+	"hema-lessons/internal/store"
+)
+
+// Routes returns the API's route table, to be served with router.New.
+// Literal segments take precedence over wildcards, so /api/items/{id}/graph
+// is matched before /api/items/{resource}/{slug}.
+func Routes(repo store.ContentRepository) []router.Route {
+	resources := NewResourceHandler(repo)
+	sections := NewSectionHandler(repo)
+	items := NewItemHandler(repo)
+	authors := NewAuthorHandler(repo)
+	search := NewSearchHandler(repo)
+	suggest := NewSuggestHandler(repo)
+	tags := NewTagHandler(repo)
+
+	return []router.Route{
+		{Method: http.MethodGet, Path: "/api/resources", Handler: resources.List},
+		{Method: http.MethodGet, Path: "/api/resources/{resource}", Handler: resources.Get},
+		{Method: http.MethodGet, Path: "/api/resources/{resource}/sections", Handler: sections.ListByBook},
+		{Method: http.MethodGet, Path: "/api/resources/{resource}/tree", Handler: resources.Tree},
+
+		{Method: http.MethodGet, Path: "/api/sections/{id}", Handler: sections.Get},
+		{Method: http.MethodGet, Path: "/api/sections/{id}/sections", Handler: sections.ListChildren},
+		{Method: http.MethodGet, Path: "/api/sections/{id}/items", Handler: items.ListBySection},
+		{Method: http.MethodGet, Path: "/api/sections/{id}/graph", Handler: sections.Graph},
+		{Method: http.MethodGet, Path: "/api/sections/{resource}/{slug}", Handler: sections.Get},
+
+		{Method: http.MethodGet, Path: "/api/items", Handler: items.List},
+		{Method: http.MethodGet, Path: "/api/items/{id}", Handler: items.Get},
+		{Method: http.MethodGet, Path: "/api/items/{id}/concordance", Handler: items.Concordance},
+		{Method: http.MethodGet, Path: "/api/items/{id}/graph", Handler: items.Graph},
+		{Method: http.MethodGet, Path: "/api/items/{resource}/{slug}", Handler: items.Get},
+
+		{Method: http.MethodGet, Path: "/api/authors", Handler: authors.List},
+		{Method: http.MethodGet, Path: "/api/authors/{id}", Handler: authors.Get},
+		{Method: http.MethodGet, Path: "/api/authors/{id}/resources", Handler: authors.ListResources},
+		{Method: http.MethodGet, Path: "/api/authors/{id}/lineage", Handler: authors.Lineage},
+		{Method: http.MethodGet, Path: "/api/lineage", Handler: authors.LineageGraph},
+
+		{Method: http.MethodGet, Path: "/api/search", Handler: search.Search},
+		{Method: http.MethodGet, Path: "/api/suggest", Handler: suggest.Suggest},
+		{Method: http.MethodGet, Path: "/api/tags", Handler: tags.List},
+	}
+}
+
+// pathID reads the path value name, such as the {id} of /api/items/{id}, as
+// a positive integer. Otherwise it writes a 400 naming what, as in "invalid
+// item ID", and returns false.
+func pathID(w http.ResponseWriter, r *http.Request, name, what string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		problem.InvalidParam(w, r, name, "invalid "+what+" ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/requestid"
+	"hema-lessons/internal/router"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/testutil"
+)
+
+// serve runs h on req through the router, with the path values of whichever
+// route pattern matches req, as if h were that route's handler.
+func serve(h http.HandlerFunc, w http.ResponseWriter, req *http.Request) {
+	routes := Routes(nil)
+	for i := range routes {
+		routes[i].Handler = h
+	}
+	router.New(routes, nil).ServeHTTP(w, req)
+}
+
+func TestRoutes(t *testing.T) {
+	h := router.New(Routes(testutil.NewTestStore()), nil)
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedAllow      string
+	}{
+		{name: "resources", method: http.MethodGet, path: "/api/resources", expectedStatusCode: http.StatusOK},
+		{name: "resources with trailing slash", method: http.MethodGet, path: "/api/resources/", expectedStatusCode: http.StatusOK},
+		{name: "resource by ID", method: http.MethodGet, path: "/api/resources/1", expectedStatusCode: http.StatusOK},
+		{name: "resource by slug", method: http.MethodGet, path: "/api/resources/book-a", expectedStatusCode: http.StatusOK},
+		{name: "resource sections", method: http.MethodGet, path: "/api/resources/1/sections", expectedStatusCode: http.StatusOK},
+		{name: "resource tree", method: http.MethodGet, path: "/api/resources/book-a/tree", expectedStatusCode: http.StatusOK},
+		{name: "section", method: http.MethodGet, path: "/api/sections/1", expectedStatusCode: http.StatusOK},
+		{name: "child sections", method: http.MethodGet, path: "/api/sections/1/sections", expectedStatusCode: http.StatusOK},
+		{name: "section items", method: http.MethodGet, path: "/api/sections/1/items", expectedStatusCode: http.StatusOK},
+		{name: "section graph", method: http.MethodGet, path: "/api/sections/1/graph", expectedStatusCode: http.StatusOK},
+		{name: "section by slug", method: http.MethodGet, path: "/api/sections/book-a/chapter-2", expectedStatusCode: http.StatusOK},
+		{name: "items", method: http.MethodGet, path: "/api/items", expectedStatusCode: http.StatusOK},
+		{name: "item", method: http.MethodGet, path: "/api/items/1", expectedStatusCode: http.StatusOK},
+		{name: "item concordance", method: http.MethodGet, path: "/api/items/1/concordance", expectedStatusCode: http.StatusOK},
+		{name: "item graph", method: http.MethodGet, path: "/api/items/1/graph", expectedStatusCode: http.StatusOK},
+		{name: "item by slug", method: http.MethodGet, path: "/api/items/book-a/technique-2", expectedStatusCode: http.StatusOK},
+		{name: "authors", method: http.MethodGet, path: "/api/authors", expectedStatusCode: http.StatusOK},
+		{name: "author", method: http.MethodGet, path: "/api/authors/1", expectedStatusCode: http.StatusOK},
+		{name: "author resources", method: http.MethodGet, path: "/api/authors/1/resources", expectedStatusCode: http.StatusOK},
+		{name: "author lineage", method: http.MethodGet, path: "/api/authors/1/lineage", expectedStatusCode: http.StatusOK},
+		{name: "lineage", method: http.MethodGet, path: "/api/lineage", expectedStatusCode: http.StatusOK},
+		{name: "search", method: http.MethodGet, path: "/api/search?q=technique", expectedStatusCode: http.StatusOK},
+		{name: "suggest", method: http.MethodGet, path: "/api/suggest?prefix=tech", expectedStatusCode: http.StatusOK},
+		{name: "tags", method: http.MethodGet, path: "/api/tags", expectedStatusCode: http.StatusOK},
+		{name: "HEAD", method: http.MethodHead, path: "/api/items/1", expectedStatusCode: http.StatusOK},
+		{
+			name:               "OPTIONS",
+			method:             http.MethodOptions,
+			path:               "/api/items/1/graph",
+			expectedStatusCode: http.StatusNoContent,
+			expectedAllow:      "GET, HEAD, OPTIONS",
+		},
+		{
+			name:               "wrong method",
+			method:             http.MethodPost,
+			path:               "/api/resources",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedAllow:      "GET, HEAD, OPTIONS",
+		},
+		{
+			name:               "wrong method on a slug path",
+			method:             http.MethodDelete,
+			path:               "/api/items/book-a/technique-2",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedAllow:      "GET, HEAD, OPTIONS",
+		},
+		{name: "unknown path", method: http.MethodGet, path: "/api/widgets", expectedStatusCode: http.StatusNotFound},
+		{name: "too many segments", method: http.MethodGet, path: "/api/items/1/graph/dot", expectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d: %s", tt.expectedStatusCode, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Allow"); got != tt.expectedAllow {
+				t.Errorf("expected Allow %q, got %q", tt.expectedAllow, got)
+			}
+		})
+	}
+}
+
+// TestRoutes_ReservedSlugs requests a section slugged "graph" through its
+// permalink. /api/sections/{id}/graph wins over the permalink, so such a
+// section cannot be reached, and content giving a section that slug is
+// refused when it is loaded.
+func TestRoutes_ReservedSlugs(t *testing.T) {
+	sections := testutil.TestSections()
+	sections[2].Title = "Graph"
+
+	unvalidated := store.NewFromData(testutil.TestAuthors(), testutil.TestResources(), sections, testutil.TestItems(), nil, nil)
+	w := httptest.NewRecorder()
+	router.New(Routes(unvalidated), nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sections/book-a/graph", nil))
+	if w.Code == http.StatusOK {
+		t.Fatalf("expected the section graph route to answer, got the permalink: %s", w.Body.String())
+	}
+
+	dir := testutil.WriteDataDir(t)
+	testutil.WriteDataFile(t, dir, "sections.json", sections)
+	_, err := store.Load(os.DirFS(dir))
+	var verr *store.ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), `sections.json: id 3: slug "graph" is reserved`) {
+		t.Errorf("expected the reserved slug to be refused, got %v", err)
+	}
+}
+
+func TestRoutes_ProblemDetails(t *testing.T) {
+	h := router.New(Routes(testutil.NewTestStore()), nil)
+	failing := router.New(Routes(&testutil.FailingRepository{}), nil)
+
+	tests := []struct {
+		name               string
+		handler            http.Handler
+		path               string
+		expectedStatusCode int
+		expectedCode       string
+		expectedParam      string
+	}{
+		{name: "invalid ID", handler: h, path: "/api/sections/abc", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter, expectedParam: "id"},
+		{name: "invalid resource", handler: h, path: "/api/resources/Book_A/sections", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter, expectedParam: "resource"},
+		{name: "invalid slug", handler: h, path: "/api/items/book-a/Technique_2", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter, expectedParam: "slug"},
+		{name: "invalid query parameter", handler: h, path: "/api/items/1/graph?format=svg", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter, expectedParam: "format"},
+		{name: "unknown tag", handler: h, path: "/api/items?weapon=halberd", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter, expectedParam: "weapon"},
+		{name: "missing parameter", handler: h, path: "/api/search", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeMissingParameter, expectedParam: "q"},
+		{name: "record not found", handler: h, path: "/api/authors/999", expectedStatusCode: http.StatusNotFound, expectedCode: "author_not_found"},
+		{name: "route not found", handler: h, path: "/api/widgets", expectedStatusCode: http.StatusNotFound, expectedCode: problem.CodeNotFound},
+		{name: "backend failure", handler: failing, path: "/api/resources", expectedStatusCode: http.StatusInternalServerError, expectedCode: problem.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req = req.WithContext(requestid.NewContext(req.Context(), "test-request"))
+			w := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Errorf("expected Content-Type %q, got %q", problem.ContentType, ct)
+			}
+
+			var p problem.Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if p.Status != tt.expectedStatusCode {
+				t.Errorf("expected status %d in body, got %d", tt.expectedStatusCode, p.Status)
+			}
+			if p.Code != tt.expectedCode {
+				t.Errorf("expected code %q, got %q", tt.expectedCode, p.Code)
+			}
+			if p.Param != tt.expectedParam {
+				t.Errorf("expected param %q, got %q", tt.expectedParam, p.Param)
+			}
+			if p.RequestID != "test-request" {
+				t.Errorf("expected request ID %q, got %q", "test-request", p.RequestID)
+			}
+			if p.Detail == "" {
+				t.Error("expected a detail")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/store"
+)
+
+type SearchHandler struct {
+	store store.ContentRepository
+}
+
+func NewSearchHandler(s store.ContentRepository) *SearchHandler {
+	return &SearchHandler{store: s}
+}
+
+// Search handles GET /api/search?q= — returns ranked, paginated hits across
+// resources, sections and items, each with its breadcrumb.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		problem.MissingParam(w, r, "q", "missing search query")
+		return
+	}
+
+	params := pagination.ParseParams(r)
+
+	hits, totalCount, err := store.SearchContent(repo, query, params)
+	if err != nil {
+		log.Printf("failed to search for %q: %v", query, err)
+		problem.Internal(w, r, "failed to search")
+		return
+	}
+
+	response := pagination.NewResponse(hits, params, totalCount)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/testutil"
+)
+
+func TestSearchHandler_Search(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewSearchHandler(s)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedTotal      int
+		expectedFirst      string
+	}{
+		{
+			name:               "items across sections",
+			query:              "?q=technique",
+			expectedStatusCode: http.StatusOK,
+			expectedTotal:      4,
+			expectedFirst:      "item:1",
+		},
+		{
+			name:               "case-insensitive, paginated",
+			query:              "?q=TECHNIQUE&page=2&page_size=3",
+			expectedStatusCode: http.StatusOK,
+			expectedTotal:      4,
+			expectedFirst:      "item:6",
+		},
+		{
+			name:               "title match ranks above description matches",
+			query:              "?q=book+b",
+			expectedStatusCode: http.StatusOK,
+			expectedTotal:      3,
+			expectedFirst:      "resource:2",
+		},
+		{
+			name:               "no matches",
+			query:              "?q=messer",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missing query",
+			query:              "?q=+",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/search"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Search, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				pagination.Response
+				Data []store.SearchHit `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.TotalCount != tt.expectedTotal {
+				t.Errorf("expected total count %d, got %d", tt.expectedTotal, response.TotalCount)
+			}
+			if tt.expectedFirst == "" {
+				if len(response.Data) != 0 {
+					t.Errorf("expected no hits, got %d", len(response.Data))
+				}
+				return
+			}
+			if len(response.Data) == 0 {
+				t.Fatal("expected hits")
+			}
+			first := response.Data[0]
+			if got := first.Type + ":" + strconv.Itoa(first.ID); got != tt.expectedFirst {
+				t.Errorf("expected first hit %s, got %s", tt.expectedFirst, got)
+			}
+		})
+	}
+}
+
+func TestSearchHandler_Breadcrumbs(t *testing.T) {
+	handler := NewSearchHandler(testutil.NewTestStore())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=nested", nil)
+	w := httptest.NewRecorder()
+	serve(handler.Search, w, req)
+
+	var response struct {
+		Data []store.SearchHit `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	crumbs := make(map[string][]int)
+	for _, hit := range response.Data {
+		var ids []int
+		for _, c := range hit.Breadcrumb {
+			ids = append(ids, c.ID)
+		}
+		crumbs[hit.Type+":"+strconv.Itoa(hit.ID)] = ids
+	}
+
+	// Item 6 lives in section 6, a child of section 1 in resource 1; the
+	// section hit's breadcrumb stops at its parent.
+	if got := crumbs["item:6"]; len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 6 {
+		t.Errorf("expected item 6 breadcrumb [1 1 6], got %v", got)
+	}
+	if got := crumbs["section:6"]; len(got) != 2 || got[0] != 1 || got[1] != 1 {
+		t.Errorf("expected section 6 breadcrumb [1 1], got %v", got)
+	}
+}
+
+func TestSearchHandler_RepositoryError(t *testing.T) {
+	handler := NewSearchHandler(testutil.FailingRepository{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=technique", nil)
+	w := httptest.NewRecorder()
+	serve(handler.Search, w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -4,136 +4,203 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
-	"strings"
 
+	"hema-lessons/internal/problem"
 	"hema-lessons/internal/store"
+	"hema-lessons/internal/techgraph"
 )
 
 type SectionHandler struct {
-	store *store.Store
+	store store.ContentRepository
 }
 
-func NewSectionHandler(s *store.Store) *SectionHandler {
+func NewSectionHandler(s store.ContentRepository) *SectionHandler {
 	return &SectionHandler{store: s}
 }
 
-// ListByResource handles GET /api/resources/:id/sections — returns root-level sections for a resource.
+// ListByResource handles GET /api/resources/{resource}/sections — returns root-level sections for a resource.
 func (h *SectionHandler) ListByBook(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	repo := h.store.View()
 
-	prefix := "/api/resources/"
-	if !strings.HasPrefix(path, prefix) {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	resourceID, ok := resolveResourceID(w, r, repo, "/api/resources/", r.PathValue("resource"))
+	if !ok {
 		return
 	}
 
-	remaining := path[len(prefix):]
-	parts := strings.Split(remaining, "/")
-
-	if len(parts) != 2 || parts[1] != "sections" {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	exists, err := repo.ResourceExists(resourceID)
+	if err != nil {
+		log.Printf("failed to look up resource %d: %v", resourceID, err)
+		problem.Internal(w, r, "failed to list sections")
 		return
 	}
-
-	resourceID, err := strconv.Atoi(parts[0])
-	if err != nil || resourceID <= 0 {
-		http.Error(w, "invalid resource ID", http.StatusBadRequest)
+	if !exists {
+		problem.NotFound(w, r, "resource")
 		return
 	}
 
-	if !h.store.ResourceExists(resourceID) {
-		http.Error(w, "resource not found", http.StatusNotFound)
+	sections, err := repo.ListRootSectionsByResourceID(resourceID)
+	if err != nil {
+		log.Printf("failed to list sections for resource %d: %v", resourceID, err)
+		problem.Internal(w, r, "failed to list sections")
 		return
 	}
 
-	sections := h.store.ListRootSectionsByResourceID(resourceID)
-
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(sections); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
 
-// Get handles GET /api/sections/:id — returns a single section.
+// Get handles GET /api/sections/{id} and GET /api/sections/{resource}/{slug} —
+// returns a single section with its breadcrumb and navigation links.
 func (h *SectionHandler) Get(w http.ResponseWriter, r *http.Request) {
-	id, ok := parseSectionID(w, r.URL.Path)
+	repo := h.store.View()
+
+	id, ok := resolveSectionID(w, r, repo)
 	if !ok {
 		return
 	}
 
-	section := h.store.GetSectionByID(id)
+	section, err := store.GetSectionDetail(repo, id)
+	if err != nil {
+		log.Printf("failed to get section %d: %v", id, err)
+		problem.Internal(w, r, "failed to get section")
+		return
+	}
 	if section == nil {
-		http.Error(w, "section not found", http.StatusNotFound)
+		problem.NotFound(w, r, "section")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(section); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
 
-// ListChildren handles GET /api/sections/:id/sections — returns child sections.
+// ListChildren handles GET /api/sections/{id}/sections — returns child sections.
 func (h *SectionHandler) ListChildren(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	repo := h.store.View()
+
+	parentID, ok := pathID(w, r, "id", "section")
+	if !ok {
+		return
+	}
+
+	parent, err := repo.GetSectionByID(parentID)
+	if err != nil {
+		log.Printf("failed to get section %d: %v", parentID, err)
+		problem.Internal(w, r, "failed to list sections")
+		return
+	}
+	if parent == nil {
+		problem.NotFound(w, r, "section")
+		return
+	}
+
+	sections, err := repo.ListChildSections(parentID)
+	if err != nil {
+		log.Printf("failed to list child sections of %d: %v", parentID, err)
+		problem.Internal(w, r, "failed to list sections")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sections); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// SectionGraphResponse is the technique graph of a section.
+type SectionGraphResponse struct {
+	SectionID int `json:"section_id"`
+	techgraph.Graph
+}
+
+// Graph handles GET /api/sections/{id}/graph — returns the section's items and
+// the counter, follow-up and remedy relations between them, as JSON or, with
+// ?format=dot, as a Graphviz digraph.
+func (h *SectionHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	repo := h.store.View()
 
-	prefix := "/api/sections/"
-	if !strings.HasPrefix(path, prefix) {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	id, ok := pathID(w, r, "id", "section")
+	if !ok {
 		return
 	}
 
-	remaining := path[len(prefix):]
-	parts := strings.Split(remaining, "/")
+	format, ok := parseGraphFormat(w, r)
+	if !ok {
+		return
+	}
 
-	if len(parts) != 2 || parts[1] != "sections" {
-		http.Error(w, "invalid path", http.StatusBadRequest)
+	section, err := repo.GetSectionByID(id)
+	if err != nil {
+		log.Printf("failed to get section %d: %v", id, err)
+		problem.Internal(w, r, "failed to get graph")
+		return
+	}
+	if section == nil {
+		problem.NotFound(w, r, "section")
 		return
 	}
 
-	parentID, err := strconv.Atoi(parts[0])
-	if err != nil || parentID <= 0 {
-		http.Error(w, "invalid section ID", http.StatusBadRequest)
+	graph, err := repo.SectionGraph(id)
+	if err != nil {
+		log.Printf("failed to get graph for section %d: %v", id, err)
+		problem.Internal(w, r, "failed to get graph")
 		return
 	}
 
-	if h.store.GetSectionByID(parentID) == nil {
-		http.Error(w, "section not found", http.StatusNotFound)
+	if format == graphFormatDOT {
+		writeGraphDOT(w, *graph, section.Title)
 		return
 	}
 
-	sections := h.store.ListChildSections(parentID)
+	response := SectionGraphResponse{SectionID: id, Graph: *graph}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(sections); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		problem.Internal(w, r, "failed to encode response")
 	}
 }
 
-// parseSectionID extracts an integer ID from a path of the form /api/sections/:id
-// (without a trailing segment). Returns false and writes an error response if invalid.
-func parseSectionID(w http.ResponseWriter, path string) (int, bool) {
-	prefix := "/api/sections/"
-	if !strings.HasPrefix(path, prefix) {
-		http.Error(w, "invalid path", http.StatusBadRequest)
-		return 0, false
+// resolveSectionID reads a section ID from /api/sections/{id}, or looks the
+// section up by slug from /api/sections/{resource}/{slug}, where {resource} is
+// a resource ID or slug. Former slugs are redirected. Returns false if a
+// response has been written.
+func resolveSectionID(w http.ResponseWriter, r *http.Request, repo store.ContentRepository) (int, bool) {
+	slug := r.PathValue("slug")
+	if slug == "" {
+		return pathID(w, r, "id", "section")
 	}
 
-	idStr := path[len(prefix):]
-	if idStr == "" {
-		http.Error(w, "invalid section ID", http.StatusBadRequest)
+	resource := r.PathValue("resource")
+	resourceID, ok := resolveResourceID(w, r, repo, "/api/sections/", resource)
+	if !ok {
 		return 0, false
 	}
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id <= 0 {
-		http.Error(w, "invalid section ID", http.StatusBadRequest)
+	if !isSlug(slug) {
+		problem.InvalidParam(w, r, "slug", "invalid section slug")
 		return 0, false
 	}
 
-	return id, true
+	section, err := repo.GetSectionBySlug(resourceID, slug)
+	if err != nil {
+		log.Printf("failed to look up section %q of resource %d: %v", slug, resourceID, err)
+		problem.Internal(w, r, "failed to get section")
+		return 0, false
+	}
+	if section == nil {
+		problem.NotFound(w, r, "section")
+		return 0, false
+	}
+	if section.Slug != slug {
+		redirectToSlug(w, r, "/api/sections/"+resource+"/", slug, section.Slug)
+		return 0, false
+	}
+	return section.ID, true
 }
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"hema-lessons/internal/models"
+	"hema-lessons/internal/store"
 	"hema-lessons/internal/testutil"
 )
 
@@ -39,8 +41,13 @@ func TestSectionHandler_ListByBook(t *testing.T) {
 			expectedCount:      0,
 		},
 		{
-			name:               "invalid resource ID - string",
+			name:               "unknown resource slug",
 			path:               "/api/resources/abc/sections",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid resource ID - string",
+			path:               "/api/resources/Book_A/sections",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -65,7 +72,7 @@ func TestSectionHandler_ListByBook(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
 			w := httptest.NewRecorder()
 
-			handler.ListByBook(w, req)
+			serve(handler.ListByBook, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -91,7 +98,7 @@ func TestSectionHandler_ListByBook_VerifyOrdering(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources/1/sections", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListByBook(w, req)
+	serve(handler.ListByBook, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -117,7 +124,7 @@ func TestSectionHandler_ListByBook_VerifyFields(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources/1/sections", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListByBook(w, req)
+	serve(handler.ListByBook, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -149,7 +156,7 @@ func TestSectionHandler_ListByBook_OnlyReturnsRootSections(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources/1/sections", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListByBook(w, req)
+	serve(handler.ListByBook, w, req)
 
 	var sections []models.Section
 	if err := json.NewDecoder(w.Body).Decode(&sections); err != nil {
@@ -174,7 +181,7 @@ func TestSectionHandler_ListByBook_EmptyResult(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/api/resources/1/sections", nil)
 	w := httptest.NewRecorder()
 
-	handler.ListByBook(w, req)
+	serve(handler.ListByBook, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
@@ -229,7 +236,7 @@ func TestSectionHandler_Get(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
 			w := httptest.NewRecorder()
 
-			handler.Get(w, req)
+			serve(handler.Get, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -287,7 +294,7 @@ func TestSectionHandler_ListChildren(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
 			w := httptest.NewRecorder()
 
-			handler.ListChildren(w, req)
+			serve(handler.ListChildren, w, req)
 
 			if w.Code != tt.expectedStatusCode {
 				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
@@ -305,3 +312,187 @@ func TestSectionHandler_ListChildren(t *testing.T) {
 		})
 	}
 }
+
+func TestSectionHandler_Graph(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewSectionHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedNodes      int
+		expectedEdges      int
+	}{
+		{
+			name:               "edges within the section only",
+			path:               "/api/sections/1/graph",
+			expectedStatusCode: http.StatusOK,
+			expectedNodes:      3,
+			expectedEdges:      2,
+		},
+		{
+			name:               "section without items",
+			path:               "/api/sections/3/graph",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "non-existent section",
+			path:               "/api/sections/999/graph",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "invalid section ID",
+			path:               "/api/sections/abc/graph",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown format",
+			path:               "/api/sections/1/graph?format=png",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Graph, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response SectionGraphResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Nodes == nil || response.Edges == nil {
+				t.Fatal("expected nodes and edges to be JSON arrays")
+			}
+			if len(response.Nodes) != tt.expectedNodes {
+				t.Errorf("expected %d nodes, got %d", tt.expectedNodes, len(response.Nodes))
+			}
+			if len(response.Edges) != tt.expectedEdges {
+				t.Errorf("expected %d edges, got %d", tt.expectedEdges, len(response.Edges))
+			}
+		})
+	}
+}
+
+func TestSectionHandler_Graph_DOT(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewSectionHandler(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sections/2/graph?format=dot", nil)
+	w := httptest.NewRecorder()
+
+	serve(handler.Graph, w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	want := `digraph "Chapter 2" {
+  rankdir=LR;
+  node [shape=box, style=rounded];
+  4 [label="Basic Move"];
+  5 [label="Advanced Move"];
+  5 -> 4 [label="is_remedy_of", color="royalblue"];
+}
+`
+	if w.Body.String() != want {
+		t.Errorf("unexpected DOT output\nexpected:\n%s\ngot:\n%s", want, w.Body.String())
+	}
+}
+
+func TestSectionHandler_Get_Navigation(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewSectionHandler(s)
+
+	tests := []struct {
+		name               string
+		id                 int
+		expectedBreadcrumb []string
+		expectedPrevious   string
+		expectedNext       string
+		expectedNextItem   string
+	}{
+		{
+			name:               "first root section",
+			id:                 1,
+			expectedBreadcrumb: []string{"resource 1"},
+			expectedNext:       "section 2",
+			expectedNextItem:   "item 1",
+		},
+		{
+			name:               "nested section",
+			id:                 6,
+			expectedBreadcrumb: []string{"resource 1", "section 1"},
+			expectedNextItem:   "item 6",
+		},
+		{
+			name:               "middle root section",
+			id:                 2,
+			expectedBreadcrumb: []string{"resource 1"},
+			expectedPrevious:   "section 1",
+			expectedNext:       "section 3",
+			expectedNextItem:   "item 4",
+		},
+		{
+			name:               "last section without items",
+			id:                 3,
+			expectedBreadcrumb: []string{"resource 1"},
+			expectedPrevious:   "section 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/sections/%d", tt.id), nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Get, w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var section store.SectionDetail
+			if err := json.NewDecoder(w.Body).Decode(&section); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			var breadcrumb []string
+			for _, c := range section.Breadcrumb {
+				breadcrumb = append(breadcrumb, crumbRef(&c))
+			}
+			if fmt.Sprint(breadcrumb) != fmt.Sprint(tt.expectedBreadcrumb) {
+				t.Errorf("expected breadcrumb %v, got %v", tt.expectedBreadcrumb, breadcrumb)
+			}
+
+			nav := section.Navigation
+			if got := crumbRef(nav.Previous); got != tt.expectedPrevious {
+				t.Errorf("expected previous %q, got %q", tt.expectedPrevious, got)
+			}
+			if got := crumbRef(nav.Next); got != tt.expectedNext {
+				t.Errorf("expected next %q, got %q", tt.expectedNext, got)
+			}
+			if got := crumbRef(nav.NextItem); got != tt.expectedNextItem {
+				t.Errorf("expected next item %q, got %q", tt.expectedNextItem, got)
+			}
+		})
+	}
+}
+
+// crumbRef formats a crumb as "type id", or "" for nil.
+func crumbRef(c *store.Crumb) string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %d", c.Type, c.ID)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/textnorm"
+)
+
+// resolveResourceID reads the path segment that follows prefix, which names
+// a resource by ID or by slug. A former slug is answered with a redirect to
+// the same URL using the current slug. It returns false if it has written a
+// response. An ID is returned without checking that the resource exists.
+func resolveResourceID(w http.ResponseWriter, r *http.Request, repo store.ContentRepository, prefix, segment string) (int, bool) {
+	if id, err := strconv.Atoi(segment); err == nil {
+		if id <= 0 {
+			problem.InvalidParam(w, r, "resource", "invalid resource ID")
+			return 0, false
+		}
+		return id, true
+	}
+	if !isSlug(segment) {
+		problem.InvalidParam(w, r, "resource", "invalid resource ID")
+		return 0, false
+	}
+
+	resource, err := repo.GetResourceBySlug(segment)
+	if err != nil {
+		log.Printf("failed to look up resource %q: %v", segment, err)
+		problem.Internal(w, r, "failed to get resource")
+		return 0, false
+	}
+	if resource == nil {
+		problem.NotFound(w, r, "resource")
+		return 0, false
+	}
+	if resource.Slug != segment {
+		redirectToSlug(w, r, prefix, segment, resource.Slug)
+		return 0, false
+	}
+	return resource.ID, true
+}
+
+// redirectToSlug answers a request whose path holds a former slug right after
+// prefix with a 301 to the same path and query using the current slug.
+func redirectToSlug(w http.ResponseWriter, r *http.Request, prefix, former, current string) {
+	target := prefix + current + r.URL.Path[len(prefix)+len(former):]
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// isSlug reports whether s is a well-formed slug, such as
+// "posta-di-finestra-window-guard".
+func isSlug(s string) bool {
+	return s != "" && textnorm.Slugify(s) == s
+}
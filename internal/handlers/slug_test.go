@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hema-lessons/internal/testutil"
+)
+
+func TestSlugLookups(t *testing.T) {
+	s := testutil.NewTestStore()
+	resources := NewResourceHandler(s)
+	sections := NewSectionHandler(s)
+	items := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		path               string
+		handler            http.HandlerFunc
+		expectedStatusCode int
+		expectedID         int
+		expectedLocation   string
+	}{
+		{
+			name:               "resource by slug",
+			path:               "/api/resources/book-b",
+			handler:            resources.Get,
+			expectedStatusCode: http.StatusOK,
+			expectedID:         2,
+		},
+		{
+			name:               "resource by former slug",
+			path:               "/api/resources/second-book",
+			handler:            resources.Get,
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/resources/book-b",
+		},
+		{
+			name:               "resource tree by former slug keeps the rest of the URL",
+			path:               "/api/resources/second-book/tree?include=items",
+			handler:            resources.Tree,
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/resources/book-b/tree?include=items",
+		},
+		{
+			name:               "unknown resource slug",
+			path:               "/api/resources/book-z",
+			handler:            resources.Get,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "malformed resource slug",
+			path:               "/api/resources/Book_B",
+			handler:            resources.Get,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "section by slug",
+			path:               "/api/sections/book-a/sub-section-of-chapter-1",
+			handler:            sections.Get,
+			expectedStatusCode: http.StatusOK,
+			expectedID:         6,
+		},
+		{
+			name:               "section by resource ID and slug",
+			path:               "/api/sections/1/chapter-2",
+			handler:            sections.Get,
+			expectedStatusCode: http.StatusOK,
+			expectedID:         2,
+		},
+		{
+			name:               "section by former slug",
+			path:               "/api/sections/book-a/part-two",
+			handler:            sections.Get,
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/sections/book-a/chapter-2",
+		},
+		{
+			name:               "section slug of another resource",
+			path:               "/api/sections/book-b/chapter-2",
+			handler:            sections.Get,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "item by slug",
+			path:               "/api/items/book-a/technique-2",
+			handler:            items.Get,
+			expectedStatusCode: http.StatusOK,
+			expectedID:         2,
+		},
+		{
+			name:               "item by former slug",
+			path:               "/api/items/book-a/first-move",
+			handler:            items.Get,
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/items/book-a/basic-move",
+		},
+		{
+			name:               "item under a former resource slug",
+			path:               "/api/items/second-book/technique-2",
+			handler:            items.Get,
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/items/book-b/technique-2",
+		},
+		{
+			name:               "unknown item slug",
+			path:               "/api/items/book-a/technique-9",
+			handler:            items.Get,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "malformed item slug",
+			path:               "/api/items/book-a/Technique 2",
+			handler:            items.Get,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set the path directly so paths that are not valid request
+			// targets, such as ones with spaces, can be tested too.
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.path, "?")
+			w := httptest.NewRecorder()
+
+			serve(tt.handler, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tt.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tt.expectedLocation, got)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				ID   int    `json:"id"`
+				Slug string `json:"slug"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.ID != tt.expectedID {
+				t.Errorf("expected ID %d, got %d", tt.expectedID, response.ID)
+			}
+			if response.Slug == "" {
+				t.Error("expected slug to be set")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/suggest"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+type SuggestHandler struct {
+	store store.ContentRepository
+}
+
+func NewSuggestHandler(s store.ContentRepository) *SuggestHandler {
+	return &SuggestHandler{store: s}
+}
+
+// Suggest handles GET /api/suggest?prefix= — returns up to ?limit= (default
+// 10, max 50) technique, guard and section titles starting with prefix.
+func (h *SuggestHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("prefix"))
+	if prefix == "" {
+		problem.MissingParam(w, r, "prefix", "missing prefix")
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	suggestions, err := h.store.Suggest(prefix, limit)
+	if err != nil {
+		log.Printf("failed to suggest for %q: %v", prefix, err)
+		problem.Internal(w, r, "failed to suggest")
+		return
+	}
+	if suggestions == nil {
+		suggestions = []suggest.Entry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(suggestions); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hema-lessons/internal/suggest"
+	"hema-lessons/internal/testutil"
+)
+
+func TestSuggestHandler_Suggest(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewSuggestHandler(s)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedTitles     []string
+	}{
+		{
+			name:               "sections before items on ties",
+			query:              "?prefix=chap",
+			expectedStatusCode: http.StatusOK,
+			expectedTitles:     []string{"Chapter 1", "Chapter 2", "Chapter 3"},
+		},
+		{
+			name:               "limit",
+			query:              "?prefix=tech&limit=2",
+			expectedStatusCode: http.StatusOK,
+			expectedTitles:     []string{"Technique 1", "Technique 2"},
+		},
+		{
+			name:               "no matches",
+			query:              "?prefix=zzz",
+			expectedStatusCode: http.StatusOK,
+			expectedTitles:     []string{},
+		},
+		{
+			name:               "missing prefix",
+			query:              "",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/suggest"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.Suggest, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var suggestions []suggest.Entry
+			if err := json.NewDecoder(w.Body).Decode(&suggestions); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if suggestions == nil {
+				t.Fatal("expected a JSON array, got null")
+			}
+			if len(suggestions) != len(tt.expectedTitles) {
+				t.Fatalf("expected %d suggestions, got %d", len(tt.expectedTitles), len(suggestions))
+			}
+			for i, title := range tt.expectedTitles {
+				if suggestions[i].Title != title {
+					t.Errorf("expected suggestion %d to be %q, got %q", i, title, suggestions[i].Title)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestHandler_RepositoryError(t *testing.T) {
+	handler := NewSuggestHandler(testutil.FailingRepository{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/suggest?prefix=tech", nil)
+	w := httptest.NewRecorder()
+	serve(handler.Suggest, w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/taxonomy"
+)
+
+type TagHandler struct {
+	store store.ContentRepository
+}
+
+func NewTagHandler(s store.ContentRepository) *TagHandler {
+	return &TagHandler{store: s}
+}
+
+// List handles GET /api/tags — returns every weapon, guard, action and armour
+// term with the number of items tagged with it.
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.store.TagSummary()
+	if err != nil {
+		log.Printf("failed to list tags: %v", err)
+		problem.Internal(w, r, "failed to list tags")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		log.Printf("failed to encode response: %v", err)
+		problem.Internal(w, r, "failed to encode response")
+	}
+}
+
+// parseTagFilter reads ?weapon=, ?guard=, ?action= and ?armour= from the
+// query. Each may be repeated or hold comma-separated slugs. It writes a 400
+// naming the first slug that is not a term of the summary's taxonomy and
+// returns false.
+func parseTagFilter(w http.ResponseWriter, r *http.Request, summary *taxonomy.Summary) (taxonomy.Filter, bool) {
+	known := map[string][]taxonomy.TermCount{
+		taxonomy.FacetWeapon: summary.Weapons,
+		taxonomy.FacetGuard:  summary.Guards,
+		taxonomy.FacetAction: summary.Actions,
+		taxonomy.FacetArmour: summary.Armour,
+	}
+
+	filter := make(taxonomy.Filter)
+	query := r.URL.Query()
+	for _, facet := range taxonomy.Facets {
+		for _, v := range query[facet] {
+			for _, slug := range strings.Split(v, ",") {
+				slug = strings.TrimSpace(slug)
+				if slug == "" {
+					continue
+				}
+				if !hasTerm(known[facet], slug) {
+					problem.InvalidParam(w, r, facet, fmt.Sprintf("unknown %s %q", facet, slug))
+					return nil, false
+				}
+				filter[facet] = append(filter[facet], slug)
+			}
+		}
+	}
+	return filter, true
+}
+
+func hasTerm(terms []taxonomy.TermCount, slug string) bool {
+	for _, t := range terms {
+		if t.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
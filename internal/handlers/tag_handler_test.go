@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/testutil"
+)
+
+func TestTagHandler_List(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewTagHandler(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	w := httptest.NewRecorder()
+
+	serve(handler.List, w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var summary taxonomy.Summary
+	if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	counts := func(terms []taxonomy.TermCount) map[string]int {
+		m := make(map[string]int)
+		for _, tc := range terms {
+			m[tc.Slug] = tc.Count
+		}
+		return m
+	}
+
+	// Items 1-3 and 6 inherit dagger from section 1 (6 via section 6); items 4-5
+	// inherit longsword from section 2.
+	weapons := counts(summary.Weapons)
+	if weapons["dagger"] != 4 || weapons["longsword"] != 2 || weapons["spear"] != 0 {
+		t.Errorf("unexpected weapon counts: %v", weapons)
+	}
+	if len(summary.Weapons) != 3 || summary.Weapons[0].Slug != "dagger" {
+		t.Errorf("expected weapons in taxonomy order, got %+v", summary.Weapons)
+	}
+	if actions := counts(summary.Actions); actions["lock"] != 2 || actions["cut"] != 1 {
+		t.Errorf("unexpected action counts: %v", actions)
+	}
+	if armour := counts(summary.Armour); armour["unarmoured"] != 4 || armour["armoured"] != 2 {
+		t.Errorf("unexpected armour counts: %v", armour)
+	}
+}
+
+func TestTagHandler_List_BackendError(t *testing.T) {
+	handler := NewTagHandler(testutil.FailingRepository{})
+
+	w := httptest.NewRecorder()
+	serve(handler.List, w, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestItemHandler_List(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedIDs        []int
+	}{
+		{
+			name:               "no filter",
+			query:              "",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:               "weapon and action",
+			query:              "?weapon=dagger&action=lock",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{1, 6},
+		},
+		{
+			name:               "comma-separated values match any",
+			query:              "?action=lock,cut",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{1, 4, 6},
+		},
+		{
+			name:               "repeated values match any",
+			query:              "?action=thrust&action=cut",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{2, 4},
+		},
+		{
+			name:               "guard and armour",
+			query:              "?guard=posta-di-donna&armour=armoured",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{4},
+		},
+		{
+			name:               "no matches",
+			query:              "?weapon=spear",
+			expectedStatusCode: http.StatusOK,
+			expectedIDs:        []int{},
+		},
+		{
+			name:               "unknown term",
+			query:              "?weapon=axe",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/items"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			serve(handler.List, w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				pagination.Response
+				Data []store.TaggedItem `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Data == nil {
+				t.Fatal("expected a JSON array, got null")
+			}
+			if response.TotalCount != len(tt.expectedIDs) {
+				t.Errorf("expected total count %d, got %d", len(tt.expectedIDs), response.TotalCount)
+			}
+			if len(response.Data) != len(tt.expectedIDs) {
+				t.Fatalf("expected %d items, got %d", len(tt.expectedIDs), len(response.Data))
+			}
+			for i, id := range tt.expectedIDs {
+				if response.Data[i].ID != id {
+					t.Errorf("expected item %d to have ID %d, got %d", i, id, response.Data[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestItemHandler_List_EffectiveTags(t *testing.T) {
+	s := testutil.NewTestStore()
+	handler := NewItemHandler(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items?action=lock&page_size=1&page=2", nil)
+	w := httptest.NewRecorder()
+
+	serve(handler.List, w, req)
+
+	var response struct {
+		Data []store.TaggedItem `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Data) != 1 || response.Data[0].ID != 6 {
+		t.Fatalf("expected item 6 on page 2, got %+v", response.Data)
+	}
+
+	// Item 6 lives in section 6, nested under section 1.
+	tags := response.Data[0].EffectiveTags
+	if len(tags.Weapons) != 1 || tags.Weapons[0] != "dagger" || tags.Armour != "unarmoured" {
+		t.Errorf("expected tags inherited from section 1, got %+v", tags)
+	}
+	if len(tags.Actions) != 1 || tags.Actions[0] != "lock" {
+		t.Errorf("expected the item's own actions, got %+v", tags)
+	}
+}
@@ -0,0 +1,155 @@
+package imaging
+
+import (
+	"container/list"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// DiskCache keeps encoded variants as files in a directory, bounded in total
+// size. When a new variant would exceed the bound the least recently used
+// ones are deleted. Files left by an earlier process are adopted on open,
+// oldest first, so the cache survives restarts.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // of *diskEntry, most recently used at the front
+	entries map[string]*list.Element
+}
+
+type diskEntry struct {
+	key  string
+	size int64
+}
+
+// OpenDiskCache opens the cache in dir, creating the directory if needed.
+func OpenDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating image cache: %w", err)
+	}
+
+	c := &DiskCache{dir: dir, maxBytes: maxBytes, lru: list.New(), entries: make(map[string]*list.Element)}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading image cache: %w", err)
+	}
+	var infos []fs.FileInfo
+	for _, de := range dirEntries {
+		if !de.Type().IsRegular() {
+			continue
+		}
+		if filepath.Ext(de.Name()) == ".tmp" {
+			// Left by a write that never finished.
+			os.Remove(filepath.Join(dir, de.Name()))
+			continue
+		}
+		if info, err := de.Info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, info := range infos {
+		c.addLocked(info.Name(), info.Size())
+	}
+	return c, nil
+}
+
+// Get returns the cached variant stored under key.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		// Removed behind the cache's back; forget it.
+		c.mu.Lock()
+		if el, ok := c.entries[key]; ok {
+			c.removeLocked(el)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores data under key, which must be a valid file name. Data larger
+// than the whole cache is not stored.
+func (c *DiskCache) Put(key string, data []byte) error {
+	if int64(len(data)) > c.maxBytes {
+		return nil
+	}
+
+	// Write to a temporary file and rename it into place, so concurrent
+	// readers never see a partial variant.
+	tmp, err := os.CreateTemp(c.dir, "variant-*.tmp")
+	if err != nil {
+		return fmt.Errorf("caching image variant: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("caching image variant: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("caching image variant: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("caching image variant: %w", err)
+	}
+	if el, ok := c.entries[key]; ok {
+		c.size -= el.Value.(*diskEntry).size
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+	c.addLocked(key, int64(len(data)))
+	return nil
+}
+
+// Size returns the total size of the cached variants in bytes.
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// addLocked records a file already in the directory and evicts the least
+// recently used files until the cache is within its bound again.
+func (c *DiskCache) addLocked(key string, size int64) {
+	c.entries[key] = c.lru.PushFront(&diskEntry{key: key, size: size})
+	c.size += size
+	for c.size > c.maxBytes {
+		oldest := c.lru.Back()
+		c.removeLocked(oldest)
+		os.Remove(filepath.Join(c.dir, oldest.Value.(*diskEntry).key))
+	}
+}
+
+func (c *DiskCache) removeLocked(el *list.Element) {
+	e := el.Value.(*diskEntry)
+	c.lru.Remove(el)
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, err := OpenDiskCache(dir, 10)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+
+	for _, key := range []string{"a.jpeg", "b.jpeg"} {
+		if err := c.Put(key, []byte("1234")); err != nil {
+			t.Fatalf("failed to put %s: %v", key, err)
+		}
+	}
+	c.Get("a.jpeg")
+	if err := c.Put("c.jpeg", []byte("1234")); err != nil {
+		t.Fatalf("failed to put c.jpeg: %v", err)
+	}
+
+	if _, ok := c.Get("b.jpeg"); ok {
+		t.Error("expected b.jpeg to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.jpeg")); !os.IsNotExist(err) {
+		t.Error("expected b.jpeg to be deleted from disk")
+	}
+	for _, key := range []string{"a.jpeg", "c.jpeg"} {
+		if data, ok := c.Get(key); !ok || string(data) != "1234" {
+			t.Errorf("expected %s to be kept, got %q", key, data)
+		}
+	}
+	if c.Size() != 8 {
+		t.Errorf("expected size 8, got %d", c.Size())
+	}
+
+	if err := c.Put("huge.jpeg", make([]byte, 11)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := c.Get("huge.jpeg"); ok {
+		t.Error("expected a variant larger than the cache not to be kept")
+	}
+}
+
+func TestOpenDiskCache_AdoptsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	c, err := OpenDiskCache(dir, 100)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	if err := c.Put("a.webp", []byte("variant")); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "variant-1.tmp"), []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenDiskCache(dir, 100)
+	if err != nil {
+		t.Fatalf("failed to reopen cache: %v", err)
+	}
+	if data, ok := reopened.Get("a.webp"); !ok || string(data) != "variant" {
+		t.Errorf("expected the cached variant to survive, got %q", data)
+	}
+	if reopened.Size() != int64(len("variant")) {
+		t.Errorf("expected size %d, got %d", len("variant"), reopened.Size())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "variant-1.tmp")); !os.IsNotExist(err) {
+		t.Error("expected the unfinished write to be removed")
+	}
+}
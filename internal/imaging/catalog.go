@@ -0,0 +1,73 @@
+package imaging
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Catalog describes the images under an asset directory by their URLs. Each
+// file is decoded and analysed once; it is analysed again only when its size
+// or modification time changes, so describing the same images on every
+// content reload is cheap.
+type Catalog struct {
+	fsys   fs.FS
+	prefix string
+
+	mu      sync.Mutex
+	entries map[string]catalogEntry
+}
+
+type catalogEntry struct {
+	size     int64
+	modTime  time.Time
+	metadata Metadata
+}
+
+// NewCatalog returns a catalog of the files in fsys, which is served at URLs
+// starting with prefix, such as "/assets/".
+func NewCatalog(fsys fs.FS, prefix string) *Catalog {
+	return &Catalog{fsys: fsys, prefix: prefix, entries: make(map[string]catalogEntry)}
+}
+
+// Describe returns the metadata of the image at url. The error wraps
+// fs.ErrNotExist when url is not under the catalog's prefix or names no file.
+func (c *Catalog) Describe(url string) (Metadata, error) {
+	name, ok := strings.CutPrefix(url, c.prefix)
+	if !ok || !fs.ValidPath(name) {
+		return Metadata{}, fmt.Errorf("%s: %w", url, fs.ErrNotExist)
+	}
+
+	info, err := fs.Stat(c.fsys, name)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if info.IsDir() {
+		return Metadata{}, fmt.Errorf("%s is a directory: %w", url, fs.ErrNotExist)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+		return e.metadata, nil
+	}
+
+	f, err := c.fsys.Open(name)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer f.Close()
+	img, err := Decode(f)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("decoding %s: %w", url, err)
+	}
+
+	e = catalogEntry{size: info.Size(), modTime: info.ModTime(), metadata: Analyze(img)}
+	c.mu.Lock()
+	c.entries[name] = e
+	c.mu.Unlock()
+	return e.metadata, nil
+}
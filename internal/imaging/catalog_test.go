@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCatalog_Describe(t *testing.T) {
+	fsys := fstest.MapFS{
+		"books/a/cover/cover.png": &fstest.MapFile{Data: pngData(t, 40, 20), ModTime: time.Unix(1, 0)},
+		"books/a/notes.txt":       &fstest.MapFile{Data: []byte("not an image")},
+	}
+	c := NewCatalog(fsys, "/assets/")
+
+	m, err := c.Describe("/assets/books/a/cover/cover.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Width != 40 || m.Height != 20 || m.AspectRatio != 2 || m.BlurHash == "" || m.DominantColor == "" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+
+	for _, url := range []string{
+		"/assets/books/a/missing.png",
+		"/assets/books/a",
+		"/assets/../secret.png",
+		"https://example.com/cover.png",
+	} {
+		if _, err := c.Describe(url); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Describe(%q): expected fs.ErrNotExist, got %v", url, err)
+		}
+	}
+
+	if _, err := c.Describe("/assets/books/a/notes.txt"); err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+}
+
+func TestCatalog_Describe_ReanalysesChangedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cover.png": &fstest.MapFile{Data: pngData(t, 40, 20), ModTime: time.Unix(1, 0)},
+	}
+	c := NewCatalog(fsys, "/assets/")
+	if _, err := c.Describe("/assets/cover.png"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Same size and time: the remembered metadata is returned unread.
+	fsys["cover.png"].Data = append([]byte(nil), fsys["cover.png"].Data...)
+	fsys["cover.png"].Data[len(fsys["cover.png"].Data)-1] ^= 0xff
+	if m, err := c.Describe("/assets/cover.png"); err != nil || m.Width != 40 {
+		t.Fatalf("expected the remembered metadata, got %+v, %v", m, err)
+	}
+
+	fsys["cover.png"] = &fstest.MapFile{Data: pngData(t, 10, 30), ModTime: time.Unix(2, 0)}
+	m, err := c.Describe("/assets/cover.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Width != 10 || m.Height != 30 {
+		t.Errorf("expected the new 10x30 image, got %dx%d", m.Width, m.Height)
+	}
+}
+
+func pngData(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 0xc8, G: 0xb4, B: 0x8c, A: 0xff}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
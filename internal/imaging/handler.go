@@ -0,0 +1,179 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"hema-lessons/internal/problem"
+)
+
+// Handler serves the files under root at URL paths starting with prefix, like
+// http.StripPrefix over http.FileServer, and variants of its images when the
+// request has w, h or fmt query parameters. Variants are generated on first
+// request and kept in cache; a variant whose source file changes gets a new
+// cache key. At most GOMAXPROCS variants are generated at once.
+func Handler(prefix, root string, cache *DiskCache) http.Handler {
+	return &variantHandler{
+		prefix:   prefix,
+		root:     http.Dir(root),
+		files:    http.StripPrefix(prefix, http.FileServer(http.Dir(root))),
+		cache:    cache,
+		slots:    make(chan struct{}, runtime.GOMAXPROCS(0)),
+		inflight: make(map[string]*flight),
+	}
+}
+
+type variantHandler struct {
+	prefix string
+	root   http.Dir
+	files  http.Handler
+	cache  *DiskCache
+	slots  chan struct{} // one per variant being generated
+
+	mu       sync.Mutex
+	inflight map[string]*flight
+}
+
+// flight is a variant being generated. Concurrent requests for the same
+// variant wait for it rather than generating it again.
+type flight struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// errNotImage marks a source file that cannot be decoded as an image.
+var errNotImage = errors.New("not an image")
+
+func (h *variantHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !IsVariant(query) {
+		h.files.ServeHTTP(w, r)
+		return
+	}
+
+	spec, err := ParseSpec(query)
+	if err != nil {
+		var perr *ParamError
+		if errors.As(err, &perr) {
+			problem.InvalidParam(w, r, perr.Param, perr.Detail)
+			return
+		}
+		problem.InvalidParam(w, r, "", err.Error())
+		return
+	}
+
+	rel, ok := strings.CutPrefix(r.URL.Path, h.prefix)
+	if !ok {
+		problem.NotFound(w, r, "image")
+		return
+	}
+	name := path.Clean("/" + rel)
+	f, err := h.root.Open(name)
+	if err != nil {
+		problem.NotFound(w, r, "image")
+		return
+	}
+	info, err := f.Stat()
+	f.Close()
+	if err != nil || info.IsDir() {
+		problem.NotFound(w, r, "image")
+		return
+	}
+
+	data, err := h.variant(name, info, spec)
+	if errors.Is(err, ErrTooLarge) {
+		log.Printf("not generating variant %s?%s: %v", name, spec, err)
+		problem.NotFound(w, r, "image")
+		return
+	}
+	if errors.Is(err, errNotImage) {
+		problem.NotFound(w, r, "image")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to generate variant %s?%s: %v", name, spec, err)
+		problem.Internal(w, r, "failed to generate image variant")
+		return
+	}
+
+	w.Header().Set("Content-Type", spec.ContentType())
+	http.ServeContent(w, r, "", info.ModTime(), bytes.NewReader(data))
+}
+
+// variant returns the encoded variant of the source file, from the cache
+// when it has been generated before.
+func (h *variantHandler) variant(name string, info fs.FileInfo, spec Spec) ([]byte, error) {
+	key := cacheKey(name, info, spec)
+	if data, ok := h.cache.Get(key); ok {
+		return data, nil
+	}
+
+	h.mu.Lock()
+	if fl, ok := h.inflight[key]; ok {
+		h.mu.Unlock()
+		<-fl.done
+		return fl.data, fl.err
+	}
+	fl := &flight{done: make(chan struct{})}
+	h.inflight[key] = fl
+	h.mu.Unlock()
+
+	h.slots <- struct{}{}
+	fl.data, fl.err = h.generate(name, spec)
+	<-h.slots
+	if fl.err == nil {
+		if err := h.cache.Put(key, fl.data); err != nil {
+			log.Printf("failed to cache variant %s?%s: %v", name, spec, err)
+		}
+	}
+
+	h.mu.Lock()
+	delete(h.inflight, key)
+	h.mu.Unlock()
+	close(fl.done)
+	return fl.data, fl.err
+}
+
+func (h *variantHandler) generate(name string, spec Spec) ([]byte, error) {
+	f, err := h.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src, err := Decode(f)
+	if errors.Is(err, ErrTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNotImage, err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, Transform(src, spec), spec.Format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cacheKey names a variant's cache file. It covers the source's size and
+// modification time, so editing an image invalidates its variants.
+func cacheKey(name string, info fs.FileInfo, spec Spec) string {
+	sum := sha256.Sum256([]byte(name + "\x00" +
+		strconv.FormatInt(info.Size(), 10) + "\x00" +
+		strconv.FormatInt(info.ModTime().UnixNano(), 10) + "\x00" +
+		fmt.Sprintf("%dx%d", spec.Width, spec.Height)))
+	return hex.EncodeToString(sum[:16]) + "." + spec.Format
+}
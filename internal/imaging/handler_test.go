@@ -0,0 +1,162 @@
+package imaging
+
+import (
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/webp"
+
+	"hema-lessons/internal/problem"
+)
+
+func TestHandler(t *testing.T) {
+	root := t.TempDir()
+	writePNG(t, filepath.Join(root, "plate.png"), 200, 100)
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := OpenDiskCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	h := Handler("/assets/", root, cache)
+
+	tests := []struct {
+		name                string
+		target              string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedWidth       int
+		expectedCode        string
+	}{
+		{name: "original", target: "/assets/plate.png", expectedStatusCode: http.StatusOK, expectedContentType: "image/png", expectedWidth: 200},
+		{name: "jpeg variant", target: "/assets/plate.png?w=80", expectedStatusCode: http.StatusOK, expectedContentType: "image/jpeg", expectedWidth: 80},
+		{name: "webp variant", target: "/assets/plate.png?w=80&h=80&fmt=webp", expectedStatusCode: http.StatusOK, expectedContentType: "image/webp", expectedWidth: 80},
+		{name: "invalid width", target: "/assets/plate.png?w=-1", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter},
+		{name: "width not allowed", target: "/assets/plate.png?w=50", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter},
+		{name: "box not allowed", target: "/assets/plate.png?w=80&h=81", expectedStatusCode: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter},
+		{name: "missing file", target: "/assets/missing.png?w=80", expectedStatusCode: http.StatusNotFound, expectedCode: "image_not_found"},
+		{name: "not an image", target: "/assets/notes.txt?w=80", expectedStatusCode: http.StatusNotFound, expectedCode: "image_not_found"},
+		{name: "directory", target: "/assets/?w=80", expectedStatusCode: http.StatusNotFound, expectedCode: "image_not_found"},
+		{name: "dot segments stay under root", target: "/assets/../../plate.png?w=80", expectedStatusCode: http.StatusOK, expectedContentType: "image/jpeg", expectedWidth: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatusCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				var p problem.Problem
+				if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+					t.Fatalf("failed to decode problem: %v", err)
+				}
+				if p.Code != tt.expectedCode {
+					t.Errorf("expected code %q, got %q", tt.expectedCode, p.Code)
+				}
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.expectedContentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.expectedContentType, got)
+			}
+			var img image.Image
+			var err error
+			switch tt.expectedContentType {
+			case "image/webp":
+				img, err = webp.Decode(w.Body)
+			case "image/jpeg":
+				img, err = jpeg.Decode(w.Body)
+			default:
+				img, err = png.Decode(w.Body)
+			}
+			if err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got := img.Bounds().Dx(); got != tt.expectedWidth {
+				t.Errorf("expected width %d, got %d", tt.expectedWidth, got)
+			}
+		})
+	}
+}
+
+func TestHandler_CachesVariants(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "cover.png")
+	writePNG(t, source, 200, 100)
+
+	cache, err := OpenDiskCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	h := Handler("/assets/", root, cache)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/assets/cover.png?w=80", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get(nil)
+	size := cache.Size()
+	if size == 0 {
+		t.Fatal("expected the variant to be cached")
+	}
+	if second := get(nil); second.Body.String() != first.Body.String() || cache.Size() != size {
+		t.Error("expected the second request to be served from the cache")
+	}
+
+	lastModified := first.Header().Get("Last-Modified")
+	if w := get(http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified {
+		t.Errorf("expected status code %d, got %d", http.StatusNotModified, w.Code)
+	}
+
+	// Replacing the source yields a new variant alongside the old one.
+	writePNG(t, source, 100, 100)
+	later := info(t, source).ModTime().Add(1e9)
+	if err := os.Chtimes(source, later, later); err != nil {
+		t.Fatal(err)
+	}
+	w := get(nil)
+	img, err := jpeg.Decode(w.Body)
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if img.Bounds().Dy() != 80 {
+		t.Errorf("expected the variant of the new source, got %v", img.Bounds())
+	}
+	if cache.Size() <= size {
+		t.Error("expected a new cache entry for the changed source")
+	}
+}
+
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	if err := os.WriteFile(path, pngData(t, width, height), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func info(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi
+}
@@ -0,0 +1,255 @@
+// Package imaging derives resized, cropped and re-encoded variants of the
+// images served from /assets/. It is pure Go: JPEG and PNG sources are
+// decoded with the standard library, scaled with golang.org/x/image/draw and
+// encoded as JPEG or lossless WebP.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+
+	// Register the source formats with image.Decode.
+	_ "image/png"
+)
+
+// Output formats of a variant.
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// Widths are the sizes a variant may be scaled to with w or h alone, and
+// Boxes the w×h boxes it may be cropped to. Each variant costs a full decode
+// and encode and a place in the disk cache, so an image only has a few.
+var (
+	Widths = []int{80, 160, 320, 480, 640, 960, 1280}
+	Boxes  = [][2]int{{80, 80}, {160, 160}, {320, 320}, {320, 240}, {640, 480}}
+)
+
+// MaxSourcePixels is the largest image, in pixels, that is decoded.
+const MaxSourcePixels = 40_000_000
+
+// ErrTooLarge reports a source image with more than MaxSourcePixels pixels.
+var ErrTooLarge = errors.New("image too large")
+
+// JPEGQuality is the quality JPEG variants are encoded at.
+const JPEGQuality = 80
+
+// SrcsetWidths are the widths listed in the srcset of an asset image. Each is
+// one of Widths.
+var SrcsetWidths = []int{320, 640, 960}
+
+// Spec describes a variant. With only Width or Height set the image is scaled
+// to it, keeping its aspect ratio; with both it is scaled to cover the box and
+// cropped around its centre. Images are never enlarged: a box larger than the
+// source shrinks to fit it, keeping the box's aspect ratio.
+type Spec struct {
+	Width  int
+	Height int
+	Format string
+}
+
+// String returns the query string that requests the variant, with its
+// parameters in a fixed order.
+func (s Spec) String() string {
+	var params []string
+	if s.Width > 0 {
+		params = append(params, "w="+strconv.Itoa(s.Width))
+	}
+	if s.Height > 0 {
+		params = append(params, "h="+strconv.Itoa(s.Height))
+	}
+	if s.Format != "" && s.Format != FormatJPEG {
+		params = append(params, "fmt="+s.Format)
+	}
+	return strings.Join(params, "&")
+}
+
+// ContentType returns the media type of the variant's encoding.
+func (s Spec) ContentType() string {
+	if s.Format == FormatWebP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// IsVariant reports whether query asks for a variant rather than the
+// original file.
+func IsVariant(query url.Values) bool {
+	return query.Has("w") || query.Has("h") || query.Has("fmt")
+}
+
+// ParamError reports an invalid variant parameter.
+type ParamError struct {
+	Param  string
+	Detail string
+}
+
+func (e *ParamError) Error() string {
+	return e.Param + ": " + e.Detail
+}
+
+// ParseSpec reads a Spec from the w, h and fmt query parameters. w or h alone
+// must be one of Widths, and w and h together one of Boxes. fmt is "jpeg"
+// (the default) or "webp".
+func ParseSpec(query url.Values) (Spec, error) {
+	var spec Spec
+	for _, p := range []struct {
+		name string
+		dest *int
+	}{{"w", &spec.Width}, {"h", &spec.Height}} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return Spec{}, &ParamError{Param: p.name, Detail: "must be a positive integer"}
+		}
+		*p.dest = n
+	}
+
+	switch {
+	case spec.Width > 0 && spec.Height > 0:
+		if !slices.Contains(Boxes, [2]int{spec.Width, spec.Height}) {
+			return Spec{}, &ParamError{Param: "h", Detail: "w and h together must be one of " + boxList()}
+		}
+	case spec.Width > 0 && !slices.Contains(Widths, spec.Width):
+		return Spec{}, &ParamError{Param: "w", Detail: "must be one of " + widthList()}
+	case spec.Height > 0 && !slices.Contains(Widths, spec.Height):
+		return Spec{}, &ParamError{Param: "h", Detail: "must be one of " + widthList()}
+	}
+
+	switch f := query.Get("fmt"); f {
+	case "", FormatJPEG, "jpg":
+		spec.Format = FormatJPEG
+	case FormatWebP:
+		spec.Format = FormatWebP
+	default:
+		return Spec{}, &ParamError{Param: "fmt", Detail: fmt.Sprintf("must be %s or %s", FormatJPEG, FormatWebP)}
+	}
+	return spec, nil
+}
+
+func widthList() string {
+	sizes := make([]string, len(Widths))
+	for i, w := range Widths {
+		sizes[i] = strconv.Itoa(w)
+	}
+	return strings.Join(sizes, ", ")
+}
+
+func boxList() string {
+	boxes := make([]string, len(Boxes))
+	for i, b := range Boxes {
+		boxes[i] = fmt.Sprintf("%dx%d", b[0], b[1])
+	}
+	return strings.Join(boxes, ", ")
+}
+
+// Decode decodes an image from r, first reading its dimensions so that a
+// source of more than MaxSourcePixels is rejected with ErrTooLarge before its
+// pixels are allocated.
+func Decode(r io.Reader) (image.Image, error) {
+	// Keep what DecodeConfig reads, to replay it to Decode.
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxSourcePixels {
+		return nil, fmt.Errorf("%dx%d: %w", cfg.Width, cfg.Height, ErrTooLarge)
+	}
+	img, _, err := image.Decode(io.MultiReader(&head, r))
+	return img, err
+}
+
+// Transform returns src resized and cropped as spec describes. A spec
+// without a size returns src unchanged.
+func Transform(src image.Image, spec Spec) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 || (spec.Width == 0 && spec.Height == 0) {
+		return src
+	}
+
+	w, h := spec.Width, spec.Height
+	crop := b
+	switch {
+	case h == 0:
+		w = min(w, sw)
+		h = max(1, sh*w/sw)
+	case w == 0:
+		h = min(h, sh)
+		w = max(1, sw*h/sh)
+	default:
+		// Shrink the box to fit the source, then crop the source to the
+		// box's aspect ratio.
+		if w > sw || h > sh {
+			scale := min(float64(sw)/float64(w), float64(sh)/float64(h))
+			w = max(1, int(float64(w)*scale))
+			h = max(1, int(float64(h)*scale))
+		}
+		cw, ch := sw, sh
+		if sw*h > sh*w {
+			cw = sh * w / h
+		} else {
+			ch = sw * h / w
+		}
+		x := b.Min.X + (sw-cw)/2
+		y := b.Min.Y + (sh-ch)/2
+		crop = image.Rect(x, y, x+cw, y+ch)
+	}
+
+	if w == sw && h == sh && crop == b {
+		return src
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img in the given format.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatWebP:
+		return nativewebp.Encode(w, img, nil)
+	case FormatJPEG, "":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	}
+	return fmt.Errorf("unknown image format %q", format)
+}
+
+// Srcset returns a srcset listing the JPEG variants of an image served from
+// /assets/ at SrcsetWidths, or "" for any other URL. When the image's width
+// is known, the widths it would have to be enlarged to are left out and the
+// original is listed at its own width. JPEG is listed because the pure Go
+// WebP encoder is lossless, which makes photographs several times larger than
+// JPEG; clients can still ask for fmt=webp explicitly.
+func Srcset(assetURL string, width int) string {
+	if !strings.HasPrefix(assetURL, "/assets/") || strings.Contains(assetURL, "?") {
+		return ""
+	}
+	var candidates []string
+	for _, w := range SrcsetWidths {
+		if width > 0 && w >= width {
+			break
+		}
+		candidates = append(candidates, fmt.Sprintf("%s?%s %dw", assetURL, Spec{Width: w}, w))
+	}
+	if width > 0 {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", assetURL, width))
+	}
+	return strings.Join(candidates, ", ")
+}
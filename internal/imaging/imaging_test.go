@@ -0,0 +1,173 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"net/url"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		query         string
+		expected      Spec
+		expectedParam string
+	}{
+		{query: "w=480", expected: Spec{Width: 480, Format: FormatJPEG}},
+		{query: "w=480&fmt=webp", expected: Spec{Width: 480, Format: FormatWebP}},
+		{query: "w=320&h=240&fmt=jpg", expected: Spec{Width: 320, Height: 240, Format: FormatJPEG}},
+		{query: "h=160", expected: Spec{Height: 160, Format: FormatJPEG}},
+		{query: "fmt=webp", expected: Spec{Format: FormatWebP}},
+		{query: "w=0", expectedParam: "w"},
+		{query: "w=abc", expectedParam: "w"},
+		{query: "h=4096", expectedParam: "h"},
+		{query: "w=500", expectedParam: "w"},
+		{query: "w=480&h=100", expectedParam: "h"},
+		{query: "w=80&fmt=gif", expectedParam: "fmt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			spec, err := ParseSpec(q)
+			if tt.expectedParam != "" {
+				perr, ok := err.(*ParamError)
+				if !ok || perr.Param != tt.expectedParam {
+					t.Fatalf("expected an error for %s, got %v", tt.expectedParam, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if spec != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, spec)
+			}
+		})
+	}
+}
+
+func TestDecode_RejectsOversizedSources(t *testing.T) {
+	// A PNG header claiming 8000x8000 pixels; no pixel data follows.
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := []byte("IHDR\x00\x00\x1f\x40\x00\x00\x1f\x40\x08\x02\x00\x00\x00")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+
+	if _, err := Decode(&buf); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	img, err := Decode(bytes.NewReader(pngData(t, 30, 20)))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 30 || b.Dy() != 20 {
+		t.Errorf("expected 30x20, got %v", b)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	tests := []struct {
+		name           string
+		spec           Spec
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{name: "width", spec: Spec{Width: 100}, expectedWidth: 100, expectedHeight: 50},
+		{name: "height", spec: Spec{Height: 50}, expectedWidth: 100, expectedHeight: 50},
+		{name: "crop", spec: Spec{Width: 100, Height: 100}, expectedWidth: 100, expectedHeight: 100},
+		{name: "no enlarging", spec: Spec{Width: 800}, expectedWidth: 400, expectedHeight: 200},
+		{name: "box shrinks to fit", spec: Spec{Width: 600, Height: 600}, expectedWidth: 200, expectedHeight: 200},
+		{name: "format only", spec: Spec{Format: FormatWebP}, expectedWidth: 400, expectedHeight: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Transform(src, tt.spec).Bounds()
+			if b.Dx() != tt.expectedWidth || b.Dy() != tt.expectedHeight {
+				t.Errorf("expected %dx%d, got %dx%d", tt.expectedWidth, tt.expectedHeight, b.Dx(), b.Dy())
+			}
+		})
+	}
+}
+
+func TestTransform_CropsAroundCentre(t *testing.T) {
+	// Red on the left and right quarters, blue in the middle half.
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 100))
+	for x := 0; x < 400; x++ {
+		c := color.NRGBA{R: 255, A: 255}
+		if x >= 100 && x < 300 {
+			c = color.NRGBA{B: 255, A: 255}
+		}
+		for y := 0; y < 100; y++ {
+			src.SetNRGBA(x, y, c)
+		}
+	}
+
+	dst := Transform(src, Spec{Width: 50, Height: 50})
+	for _, x := range []int{0, 25, 49} {
+		if r, _, b, _ := dst.At(x, 25).RGBA(); r > b {
+			t.Errorf("expected only the blue centre at x=%d", x)
+		}
+	}
+}
+
+func TestEncode_WebP(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	src.SetNRGBA(1, 1, color.NRGBA{R: 200, G: 10, B: 10, A: 255})
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, src, FormatWebP); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	got, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if got.Bounds() != src.Bounds() {
+		t.Errorf("expected bounds %v, got %v", src.Bounds(), got.Bounds())
+	}
+	if r, _, _, _ := got.At(1, 1).RGBA(); r>>8 != 200 {
+		t.Errorf("expected a lossless pixel, got red %d", r>>8)
+	}
+}
+
+func TestSrcset(t *testing.T) {
+	tests := []struct {
+		url      string
+		width    int
+		expected string
+	}{
+		{
+			url:      "/assets/books/a/cover/cover.jpg",
+			expected: "/assets/books/a/cover/cover.jpg?w=320 320w, /assets/books/a/cover/cover.jpg?w=640 640w, /assets/books/a/cover/cover.jpg?w=960 960w",
+		},
+		{
+			url:      "/assets/books/a/cover/cover.jpg",
+			width:    700,
+			expected: "/assets/books/a/cover/cover.jpg?w=320 320w, /assets/books/a/cover/cover.jpg?w=640 640w, /assets/books/a/cover/cover.jpg 700w",
+		},
+		{url: "/assets/books/a/cover/cover.jpg", width: 288, expected: "/assets/books/a/cover/cover.jpg 288w"},
+		{url: "https://example.com/cover.jpg", expected: ""},
+		{url: "/assets/cover.jpg?w=10", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Srcset(tt.url, tt.width); got != tt.expected {
+			t.Errorf("Srcset(%q, %d): expected %q, got %q", tt.url, tt.width, tt.expected, got)
+		}
+	}
+}
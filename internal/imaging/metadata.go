@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// previewSize is the longest side images are reduced to before they are
+// analysed. Placeholders are blurred anyway, and analysis stays cheap.
+const previewSize = 64
+
+// Metadata lets clients lay out and preview an image before it downloads.
+type Metadata struct {
+	Width  int
+	Height int
+	// AspectRatio is Width / Height, rounded to four decimals.
+	AspectRatio float64
+	// BlurHash encodes a blurred placeholder; see BlurHash.
+	BlurHash string
+	// DominantColor is the most common colour as "#rrggbb".
+	DominantColor string
+}
+
+// Analyze measures img and computes its placeholders.
+func Analyze(img image.Image) Metadata {
+	b := img.Bounds()
+	m := Metadata{Width: b.Dx(), Height: b.Dy()}
+	if m.Width == 0 || m.Height == 0 {
+		return m
+	}
+	m.AspectRatio = math.Round(float64(m.Width)/float64(m.Height)*10000) / 10000
+
+	preview := Transform(img, Spec{Width: previewSize})
+	if m.Height > m.Width {
+		preview = Transform(img, Spec{Height: previewSize})
+	}
+	m.BlurHash = BlurHash(preview)
+	m.DominantColor = DominantColor(preview)
+	return m
+}
+
+// DominantColor returns the most common colour of img as "#rrggbb". Colours
+// are counted in buckets of 16 levels per channel, and the pixels of the
+// fullest bucket are averaged; transparent pixels are ignored.
+func DominantColor(img image.Image) string {
+	type bucket struct {
+		n       int
+		r, g, b uint64
+	}
+	var buckets [16 * 16 * 16]bucket
+
+	best := -1
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			r, g, b = r>>8, g>>8, b>>8
+			i := int(r>>4)<<8 | int(g>>4)<<4 | int(b>>4)
+			bk := &buckets[i]
+			bk.n++
+			bk.r += uint64(r)
+			bk.g += uint64(g)
+			bk.b += uint64(b)
+			if best < 0 || bk.n > buckets[best].n {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+
+	bk := buckets[best]
+	n := uint64(bk.n)
+	return fmt.Sprintf("#%02x%02x%02x", bk.r/n, bk.g/n, bk.b/n)
+}
@@ -0,0 +1,64 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestBlurHash(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 24))
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 8), G: uint8(y * 10), B: 128, A: 255})
+		}
+	}
+
+	// Matches the reference implementation at 4x3 components.
+	if got, expected := BlurHash(img), "LxH27k2swxX8mHWWjtf7gJfjfQfj"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			c := color.NRGBA{R: 0xc8, G: 0xb4, B: 0x8c, A: 255} // parchment
+			switch {
+			case x < 3:
+				c = color.NRGBA{R: 0x20, G: 0x10, B: 0x10, A: 255} // ink
+			case y == 0:
+				c = color.NRGBA{} // transparent
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	if got := DominantColor(img); got != "#c8b48c" {
+		t.Errorf("expected the parchment colour, got %q", got)
+	}
+	if got := DominantColor(image.NewNRGBA(image.Rect(0, 0, 2, 2))); got != "" {
+		t.Errorf("expected no colour for a transparent image, got %q", got)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 450))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 0x80}), image.Point{}, draw.Src)
+
+	m := Analyze(img)
+	if m.Width != 300 || m.Height != 450 {
+		t.Errorf("expected 300x450, got %dx%d", m.Width, m.Height)
+	}
+	if m.AspectRatio != 0.6667 {
+		t.Errorf("expected aspect ratio 0.6667, got %v", m.AspectRatio)
+	}
+	if len(m.BlurHash) != 28 {
+		t.Errorf("expected a 4x3 BlurHash of 28 characters, got %q", m.BlurHash)
+	}
+	if m.DominantColor != "#808080" {
+		t.Errorf("expected #808080, got %q", m.DominantColor)
+	}
+}
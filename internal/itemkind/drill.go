@@ -0,0 +1,63 @@
+package itemkind
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Drill is a repeatable exercise, usually worked with a partner.
+const Drill = "drill"
+
+func init() {
+	Register(Drill, func() Attributes { return &DrillAttributes{} })
+}
+
+// DrillAttributes are the attributes of a drill item.
+type DrillAttributes struct {
+	Instructions string      `json:"instructions"`
+	Reps         int         `json:"reps"`
+	Sets         int         `json:"sets,omitempty"`
+	Roles        []DrillRole `json:"roles,omitempty"`
+}
+
+// DrillRole is what one partner does in a drill, e.g. the agent who attacks
+// and the patient who defends.
+type DrillRole struct {
+	Role         string `json:"role"`
+	Instructions string `json:"instructions"`
+}
+
+func (a *DrillAttributes) Validate() error {
+	if strings.TrimSpace(a.Instructions) == "" {
+		return errors.New("instructions is required")
+	}
+	if a.Reps <= 0 {
+		return errors.New("reps must be positive")
+	}
+	if a.Sets < 0 {
+		return errors.New("sets must not be negative")
+	}
+	seen := make(map[string]bool, len(a.Roles))
+	for i, r := range a.Roles {
+		if strings.TrimSpace(r.Role) == "" {
+			return fmt.Errorf("roles[%d].role is required", i)
+		}
+		if strings.TrimSpace(r.Instructions) == "" {
+			return fmt.Errorf("roles[%d].instructions is required", i)
+		}
+		if seen[r.Role] {
+			return fmt.Errorf("roles[%d]: role %q is listed twice", i, r.Role)
+		}
+		seen[r.Role] = true
+	}
+	return nil
+}
+
+func (a *DrillAttributes) SearchText() []string {
+	text := []string{a.Instructions}
+	for _, r := range a.Roles {
+		text = append(text, r.Instructions)
+	}
+	return text
+}
@@ -0,0 +1,185 @@
+// Package itemkind maps each item Kind to a typed attribute schema.
+//
+// Items carry their kind-specific data in models.Item.Attributes as raw JSON.
+// A kind is registered with a constructor for its attribute struct; Decode
+// parses raw attributes strictly into that struct and runs its Validate
+// method, so callers get either typed attributes or a precise error.
+//
+// Adding a kind means defining a struct that implements Attributes and
+// registering it in init.
+package itemkind
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Attributes is the typed form of an item's attributes.
+type Attributes interface {
+	// Validate reports missing or malformed fields after decoding.
+	Validate() error
+}
+
+// Searchable attributes contribute free text to the search index.
+type Searchable interface {
+	SearchText() []string
+}
+
+// Illustrated attributes reference images served from /assets/. ImageURLs
+// maps attribute names to URLs and omits attributes that are not set.
+type Illustrated interface {
+	ImageURLs() map[string]string
+}
+
+// Registry maps item kinds to attribute constructors.
+type Registry struct {
+	kinds map[string]func() Attributes
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{kinds: make(map[string]func() Attributes)}
+}
+
+// Register adds a kind. newAttrs must return a pointer to a fresh, zero
+// attribute struct. Registering the same kind twice panics.
+func (r *Registry) Register(kind string, newAttrs func() Attributes) {
+	if _, dup := r.kinds[kind]; dup {
+		panic(fmt.Sprintf("itemkind: kind %q registered twice", kind))
+	}
+	r.kinds[kind] = newAttrs
+}
+
+// Known reports whether kind is registered.
+func (r *Registry) Known(kind string) bool {
+	_, ok := r.kinds[kind]
+	return ok
+}
+
+// Kinds returns the registered kinds in alphabetical order.
+func (r *Registry) Kinds() []string {
+	kinds := make([]string, 0, len(r.kinds))
+	for k := range r.kinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Decode parses raw into the attribute struct registered for kind and
+// validates it. Unknown fields and fields of the wrong JSON type are errors.
+// Empty or null attributes decode to the zero struct, which is then validated.
+func (r *Registry) Decode(kind string, raw json.RawMessage) (Attributes, error) {
+	newAttrs, ok := r.kinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("kind %q is not one of %s", kind, strings.Join(r.Kinds(), ", "))
+	}
+
+	attrs := newAttrs()
+	if len(bytes.TrimSpace(raw)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(attrs); err != nil {
+			return nil, decodeError(err)
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			return nil, errors.New("attributes: unexpected data after the JSON object")
+		}
+	}
+
+	if err := attrs.Validate(); err != nil {
+		return nil, fmt.Errorf("attributes: %w", err)
+	}
+	return attrs, nil
+}
+
+// Validate checks raw against the schema registered for kind.
+func (r *Registry) Validate(kind string, raw json.RawMessage) error {
+	_, err := r.Decode(kind, raw)
+	return err
+}
+
+// decodeError rewrites encoding/json errors in terms of attribute names.
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return fmt.Errorf("attributes: must be a JSON object, not %s", typeErr.Value)
+		}
+		return fmt.Errorf("attributes: %s must be %s, not %s", typeErr.Field, jsonType(typeErr.Type.Kind().String()), typeErr.Value)
+	}
+	if msg := err.Error(); strings.HasPrefix(msg, "json: unknown field ") {
+		return fmt.Errorf("attributes: unknown field %s", strings.TrimPrefix(msg, "json: unknown field "))
+	}
+	return fmt.Errorf("attributes: %w", err)
+}
+
+func jsonType(goKind string) string {
+	switch goKind {
+	case "string":
+		return "a string"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "an integer"
+	case "float32", "float64":
+		return "a number"
+	case "bool":
+		return "a boolean"
+	case "slice", "array":
+		return "an array"
+	case "struct", "map":
+		return "an object"
+	}
+	return "a " + goKind
+}
+
+// ImageURLs returns the image URLs among attributes of kind, keyed by
+// attribute name. Attributes that do not decode, or whose kind is not
+// Illustrated, have none.
+func (r *Registry) ImageURLs(kind string, raw json.RawMessage) map[string]string {
+	attrs, err := r.Decode(kind, raw)
+	if err != nil {
+		return nil
+	}
+	if ill, ok := attrs.(Illustrated); ok {
+		return ill.ImageURLs()
+	}
+	return nil
+}
+
+// Default is the registry holding every kind the application supports.
+var Default = NewRegistry()
+
+// Register adds a kind to the Default registry.
+func Register(kind string, newAttrs func() Attributes) {
+	Default.Register(kind, newAttrs)
+}
+
+// Known reports whether kind is registered in the Default registry.
+func Known(kind string) bool {
+	return Default.Known(kind)
+}
+
+// Kinds returns the kinds registered in the Default registry.
+func Kinds() []string {
+	return Default.Kinds()
+}
+
+// Decode decodes attributes using the Default registry.
+func Decode(kind string, raw json.RawMessage) (Attributes, error) {
+	return Default.Decode(kind, raw)
+}
+
+// Validate validates attributes using the Default registry.
+func Validate(kind string, raw json.RawMessage) error {
+	return Default.Validate(kind, raw)
+}
+
+// ImageURLs returns the image URLs among attributes using the Default registry.
+func ImageURLs(kind string, raw json.RawMessage) map[string]string {
+	return Default.ImageURLs(kind, raw)
+}
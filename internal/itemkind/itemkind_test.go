@@ -0,0 +1,160 @@
+package itemkind
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type verseAttributes struct {
+	Lines []string `json:"lines"`
+}
+
+func (a *verseAttributes) Validate() error {
+	if len(a.Lines) == 0 {
+		return errors.New("lines is required")
+	}
+	return nil
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	r := NewRegistry()
+	r.Register("verse", func() Attributes { return &verseAttributes{} })
+
+	attrs, err := r.Decode("verse", json.RawMessage(`{"lines": ["Io son la posta"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verse, ok := attrs.(*verseAttributes)
+	if !ok || len(verse.Lines) != 1 {
+		t.Errorf("expected typed verse attributes, got %#v", attrs)
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		raw      string
+		expected string
+	}{
+		{"missing required field", "verse", `{}`, "attributes: lines is required"},
+		{"null", "verse", `null`, "attributes: lines is required"},
+		{"empty", "verse", ``, "attributes: lines is required"},
+		{"unknown field", "verse", `{"lines": ["a"], "rhyme": true}`, `attributes: unknown field "rhyme"`},
+		{"wrong field type", "verse", `{"lines": "a"}`, "attributes: lines must be an array, not string"},
+		{"not an object", "verse", `["a"]`, "attributes: must be a JSON object, not array"},
+		{"trailing data", "verse", `{"lines": ["a"]} {}`, "attributes: unexpected data after the JSON object"},
+		{"unknown kind", "sonnet", `{}`, `kind "sonnet" is not one of verse`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Decode(tt.kind, json.RawMessage(tt.raw))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register("verse", func() Attributes { return &verseAttributes{} })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	r.Register("verse", func() Attributes { return &verseAttributes{} })
+}
+
+func TestDefault_Technique(t *testing.T) {
+	attrs, err := Decode(Technique, json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/x.jpg"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	technique, ok := attrs.(*TechniqueAttributes)
+	if !ok {
+		t.Fatalf("expected *TechniqueAttributes, got %T", attrs)
+	}
+	if technique.HistoricalImageURL != "/assets/x.jpg" {
+		t.Errorf("expected historical image URL, got %q", technique.HistoricalImageURL)
+	}
+}
+
+func TestImageURLs(t *testing.T) {
+	tests := []struct {
+		kind     string
+		raw      string
+		expected string
+	}{
+		{Technique, `{"instructions": "Step 1", "historical_image_url": "/assets/x.jpg"}`, "/assets/x.jpg"},
+		{Plate, `{"image_url": "/assets/books/x/plates/1r.jpg"}`, "/assets/books/x/plates/1r.jpg"},
+		{Technique, `{"historical_image_url": "/assets/x.jpg"}`, ""},
+		{Quote, `{"lines": ["Jung ritter lere"]}`, ""},
+		{"sonnet", `{}`, ""},
+	}
+
+	for _, tt := range tests {
+		urls := ImageURLs(tt.kind, json.RawMessage(tt.raw))
+		got := ""
+		for _, url := range urls {
+			got = url
+		}
+		if len(urls) > 1 || got != tt.expected {
+			t.Errorf("%s %s: expected %q, got %v", tt.kind, tt.raw, tt.expected, urls)
+		}
+	}
+}
+
+func TestVideoAttributes_ImageURLs(t *testing.T) {
+	tests := []struct {
+		thumbnail string
+		expected  map[string]string
+	}{
+		{"/assets/books/x/videos/1.jpg", map[string]string{"thumbnail_url": "/assets/books/x/videos/1.jpg"}},
+		{"https://img.example.com/poster.jpg", nil},
+		{"http://img.example.com/poster.jpg", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		a := &VideoAttributes{ThumbnailURL: tt.thumbnail}
+		got := a.ImageURLs()
+		if len(got) != len(tt.expected) || got["thumbnail_url"] != tt.expected["thumbnail_url"] {
+			t.Errorf("%q: expected %v, got %v", tt.thumbnail, tt.expected, got)
+		}
+	}
+}
+
+func TestDefault_Kinds(t *testing.T) {
+	tests := []struct {
+		kind     string
+		raw      string
+		expected string
+	}{
+		{Drill, `{"instructions": "Cut", "reps": 10, "sets": 3, "roles": [{"role": "agent", "instructions": "Strike"}, {"role": "patient", "instructions": "Cover"}]}`, ""},
+		{Drill, `{"instructions": "Cut"}`, "attributes: reps must be positive"},
+		{Drill, `{"instructions": "Cut", "reps": 1, "roles": [{"role": "agent"}]}`, "attributes: roles[0].instructions is required"},
+		{Drill, `{"instructions": "Cut", "reps": 1, "roles": [{"role": "agent", "instructions": "a"}, {"role": "agent", "instructions": "b"}]}`, `attributes: roles[1]: role "agent" is listed twice`},
+		{Quote, `{"lines": ["Jung ritter lere"], "language": "de", "translation": ["Young knight, learn"]}`, ""},
+		{Quote, `{"lines": []}`, "attributes: lines is required"},
+		{Quote, `{"lines": ["a", " "]}`, "attributes: lines must not contain blank lines"},
+		{Plate, `{"image_url": "/assets/books/x/plates/1r.jpg", "folio": "1r"}`, ""},
+		{Plate, `{"folio": "1r"}`, "attributes: image_url is required"},
+		{Video, `{"video_url": "https://example.com/v.mp4", "duration_seconds": 300}`, ""},
+		{Video, `{"video_url": "https://example.com/v.mp4", "duration_seconds": "5m"}`, "attributes: duration_seconds must be an integer, not string"},
+		{Video, `{"video_url": "https://example.com/v.mp4"}`, "attributes: duration_seconds must be positive"},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(tt.kind, json.RawMessage(tt.raw))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.expected {
+			t.Errorf("%s %s: expected error %q, got %q", tt.kind, tt.raw, tt.expected, got)
+		}
+	}
+}
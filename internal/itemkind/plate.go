@@ -0,0 +1,35 @@
+package itemkind
+
+import (
+	"errors"
+	"strings"
+)
+
+// Plate is a full manuscript page or illustration.
+const Plate = "plate"
+
+func init() {
+	Register(Plate, func() Attributes { return &PlateAttributes{} })
+}
+
+// PlateAttributes are the attributes of a plate item.
+type PlateAttributes struct {
+	ImageURL string `json:"image_url"`
+	Folio    string `json:"folio,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+func (a *PlateAttributes) Validate() error {
+	if strings.TrimSpace(a.ImageURL) == "" {
+		return errors.New("image_url is required")
+	}
+	return nil
+}
+
+func (a *PlateAttributes) SearchText() []string {
+	return []string{a.Caption}
+}
+
+func (a *PlateAttributes) ImageURLs() map[string]string {
+	return map[string]string{"image_url": a.ImageURL}
+}
@@ -0,0 +1,38 @@
+package itemkind
+
+import (
+	"errors"
+	"strings"
+)
+
+// Quote is a verse quotation from a treatise.
+const Quote = "quote"
+
+func init() {
+	Register(Quote, func() Attributes { return &QuoteAttributes{} })
+}
+
+// QuoteAttributes are the attributes of a quote item. Lines and Translation
+// hold one verse line per element.
+type QuoteAttributes struct {
+	Lines       []string `json:"lines"`
+	Language    string   `json:"language,omitempty"`
+	Translation []string `json:"translation,omitempty"`
+	Folio       string   `json:"folio,omitempty"`
+}
+
+func (a *QuoteAttributes) Validate() error {
+	if len(a.Lines) == 0 {
+		return errors.New("lines is required")
+	}
+	for _, line := range a.Lines {
+		if strings.TrimSpace(line) == "" {
+			return errors.New("lines must not contain blank lines")
+		}
+	}
+	return nil
+}
+
+func (a *QuoteAttributes) SearchText() []string {
+	return append(append([]string{}, a.Lines...), a.Translation...)
+}
@@ -0,0 +1,37 @@
+package itemkind
+
+import (
+	"errors"
+	"strings"
+)
+
+// Technique is an action or guard taught by a treatise.
+const Technique = "technique"
+
+func init() {
+	Register(Technique, func() Attributes { return &TechniqueAttributes{} })
+}
+
+// TechniqueAttributes are the attributes of a technique item.
+type TechniqueAttributes struct {
+	Instructions       string `json:"instructions"`
+	HistoricalImageURL string `json:"historical_image_url,omitempty"`
+}
+
+func (a *TechniqueAttributes) Validate() error {
+	if strings.TrimSpace(a.Instructions) == "" {
+		return errors.New("instructions is required")
+	}
+	return nil
+}
+
+func (a *TechniqueAttributes) SearchText() []string {
+	return []string{a.Instructions}
+}
+
+func (a *TechniqueAttributes) ImageURLs() map[string]string {
+	if a.HistoricalImageURL == "" {
+		return nil
+	}
+	return map[string]string{"historical_image_url": a.HistoricalImageURL}
+}
@@ -0,0 +1,49 @@
+package itemkind
+
+import (
+	"errors"
+	"strings"
+)
+
+// Video is a recorded video lesson.
+const Video = "video"
+
+func init() {
+	Register(Video, func() Attributes { return &VideoAttributes{} })
+}
+
+// VideoAttributes are the attributes of a video lesson item.
+type VideoAttributes struct {
+	VideoURL        string `json:"video_url"`
+	DurationSeconds int    `json:"duration_seconds"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	Instructor      string `json:"instructor,omitempty"`
+}
+
+func (a *VideoAttributes) Validate() error {
+	if strings.TrimSpace(a.VideoURL) == "" {
+		return errors.New("video_url is required")
+	}
+	if a.DurationSeconds <= 0 {
+		return errors.New("duration_seconds must be positive")
+	}
+	return nil
+}
+
+func (a *VideoAttributes) SearchText() []string {
+	return []string{a.Instructor}
+}
+
+// ImageURLs omits a thumbnail_url hosted elsewhere, such as a video
+// platform's poster, since it is not served from /assets/.
+func (a *VideoAttributes) ImageURLs() map[string]string {
+	if a.ThumbnailURL == "" || isExternal(a.ThumbnailURL) {
+		return nil
+	}
+	return map[string]string{"thumbnail_url": a.ThumbnailURL}
+}
+
+// isExternal reports whether url is an absolute http(s) URL.
+func isExternal(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
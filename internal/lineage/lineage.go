@@ -0,0 +1,185 @@
+// Package lineage turns the relations between authors into a graph of
+// masters, and into trees rooted at any one of them.
+package lineage
+
+import (
+	"sort"
+
+	"hema-lessons/internal/models"
+)
+
+// Directions in which a tree can be grown from its root.
+const (
+	// Descendants follows relations forward: students, glossators and
+	// successors of the root.
+	Descendants = "descendants"
+	// Ancestors follows relations backward: the masters the root learned
+	// from, glossed or continued.
+	Ancestors = "ancestors"
+)
+
+// Node is an author in the lineage graph.
+type Node struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	BirthYear *int   `json:"birth_year,omitempty"`
+	DeathYear *int   `json:"death_year,omitempty"`
+}
+
+// Edge is a typed relation from an earlier master to a later one.
+type Edge struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Type string `json:"type"`
+	Note string `json:"note,omitempty"`
+}
+
+// Graph is a set of authors and the relations between them.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// TreeNode is an author in a lineage tree. Relation is the type of the edge
+// that connects it to its parent and is empty for the root.
+type TreeNode struct {
+	Node
+	Relation string     `json:"relation,omitempty"`
+	Children []TreeNode `json:"children"`
+}
+
+// Lineage indexes a set of authors by their relations.
+type Lineage struct {
+	nodes    map[int]Node
+	order    []int // node IDs ordered by name
+	outgoing map[int][]Edge
+	incoming map[int][]Edge
+}
+
+// New builds a Lineage from authors and their relations. Relations pointing at
+// authors that are not in the slice are ignored.
+func New(authors []models.Author) *Lineage {
+	l := &Lineage{
+		nodes:    make(map[int]Node, len(authors)),
+		outgoing: make(map[int][]Edge),
+		incoming: make(map[int][]Edge),
+	}
+
+	sorted := make([]models.Author, len(authors))
+	copy(sorted, authors)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	for _, a := range sorted {
+		l.nodes[a.ID] = Node{ID: a.ID, Name: a.Name, BirthYear: a.BirthYear, DeathYear: a.DeathYear}
+		l.order = append(l.order, a.ID)
+	}
+	for _, a := range sorted {
+		for _, rel := range a.Relations {
+			if _, ok := l.nodes[rel.AuthorID]; !ok {
+				continue
+			}
+			e := Edge{From: a.ID, To: rel.AuthorID, Type: rel.Type, Note: rel.Note}
+			l.outgoing[e.From] = append(l.outgoing[e.From], e)
+			l.incoming[e.To] = append(l.incoming[e.To], e)
+		}
+	}
+
+	return l
+}
+
+// Has reports whether the author is part of the lineage.
+func (l *Lineage) Has(authorID int) bool {
+	_, ok := l.nodes[authorID]
+	return ok
+}
+
+// Graph returns every author and every relation.
+func (l *Lineage) Graph() Graph {
+	return l.subgraph(func(int) bool { return true })
+}
+
+// Component returns the authors connected to authorID by relations in either
+// direction, together with the relations between them.
+func (l *Lineage) Component(authorID int) Graph {
+	if !l.Has(authorID) {
+		return Graph{Nodes: []Node{}, Edges: []Edge{}}
+	}
+
+	seen := map[int]bool{authorID: true}
+	queue := []int{authorID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range l.outgoing[id] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+		for _, e := range l.incoming[id] {
+			if !seen[e.From] {
+				seen[e.From] = true
+				queue = append(queue, e.From)
+			}
+		}
+	}
+
+	return l.subgraph(func(id int) bool { return seen[id] })
+}
+
+func (l *Lineage) subgraph(include func(id int) bool) Graph {
+	g := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for _, id := range l.order {
+		if !include(id) {
+			continue
+		}
+		g.Nodes = append(g.Nodes, l.nodes[id])
+		for _, e := range l.outgoing[id] {
+			if include(e.To) {
+				g.Edges = append(g.Edges, e)
+			}
+		}
+	}
+	return g
+}
+
+// Tree returns the lineage rooted at authorID, grown in the given direction
+// (Descendants or Ancestors). A master reachable along several paths appears
+// under each of them; a relation that would loop back to an author already on
+// the current path is not followed. It returns nil if the author is unknown.
+func (l *Lineage) Tree(authorID int, direction string) *TreeNode {
+	if !l.Has(authorID) {
+		return nil
+	}
+
+	next := func(id int) []Edge { return l.outgoing[id] }
+	other := func(e Edge) int { return e.To }
+	if direction == Ancestors {
+		next = func(id int) []Edge { return l.incoming[id] }
+		other = func(e Edge) int { return e.From }
+	}
+
+	onPath := make(map[int]bool)
+	var grow func(id int, relation string) TreeNode
+	grow = func(id int, relation string) TreeNode {
+		onPath[id] = true
+		defer delete(onPath, id)
+
+		node := TreeNode{Node: l.nodes[id], Relation: relation, Children: []TreeNode{}}
+		for _, e := range next(id) {
+			if onPath[other(e)] {
+				continue
+			}
+			node.Children = append(node.Children, grow(other(e), e.Type))
+		}
+		return node
+	}
+
+	root := grow(authorID, "")
+	return &root
+}
@@ -0,0 +1,128 @@
+package lineage
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/testutil"
+)
+
+func TestLineage_Graph(t *testing.T) {
+	authors := append(testutil.TestAuthors(), models.Author{ID: 4, Name: "Unrelated"})
+	g := New(authors).Graph()
+
+	if len(g.Nodes) != 4 {
+		t.Errorf("expected 4 nodes, got %d", len(g.Nodes))
+	}
+	want := []Edge{
+		{From: 1, To: 2, Type: models.RelationTeacherOf},
+		{From: 1, To: 3, Type: models.RelationTradition, Note: "Later follower"},
+		{From: 2, To: 3, Type: models.RelationGlossedBy},
+	}
+	if !reflect.DeepEqual(g.Edges, want) {
+		t.Errorf("unexpected edges\nexpected: %+v\ngot:      %+v", want, g.Edges)
+	}
+}
+
+func TestLineage_Component(t *testing.T) {
+	authors := append(testutil.TestAuthors(), models.Author{ID: 4, Name: "Unrelated"})
+	l := New(authors)
+
+	g := l.Component(2)
+	if ids := nodeIDs(g.Nodes); !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("expected nodes [1 2 3], got %v", ids)
+	}
+	if len(g.Edges) != 3 {
+		t.Errorf("expected 3 edges, got %d", len(g.Edges))
+	}
+
+	g = l.Component(4)
+	if ids := nodeIDs(g.Nodes); !reflect.DeepEqual(ids, []int{4}) || len(g.Edges) != 0 {
+		t.Errorf("expected isolated author 4, got %+v", g)
+	}
+}
+
+func TestLineage_Tree(t *testing.T) {
+	l := New(testutil.TestAuthors())
+
+	tests := []struct {
+		name      string
+		root      int
+		direction string
+		expected  string
+	}{
+		{
+			name:      "descendants reach a master along every path",
+			root:      1,
+			direction: Descendants,
+			expected:  "1(2[teacher_of](3[glossed_by]) 3[tradition])",
+		},
+		{
+			name:      "ancestors",
+			root:      3,
+			direction: Ancestors,
+			expected:  "3(1[tradition] 2[glossed_by](1[teacher_of]))",
+		},
+		{
+			name:      "leaf",
+			root:      3,
+			direction: Descendants,
+			expected:  "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := l.Tree(tt.root, tt.direction)
+			if tree == nil {
+				t.Fatal("expected a tree")
+			}
+			if got := formatTree(*tree); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if l.Tree(99, Descendants) != nil {
+		t.Error("expected nil tree for unknown author")
+	}
+}
+
+func TestLineage_Tree_BreaksCycles(t *testing.T) {
+	authors := []models.Author{
+		{ID: 1, Name: "A", Relations: []models.AuthorRelation{{Type: models.RelationTeacherOf, AuthorID: 2}}},
+		{ID: 2, Name: "B", Relations: []models.AuthorRelation{{Type: models.RelationTeacherOf, AuthorID: 1}}},
+	}
+
+	tree := New(authors).Tree(1, Descendants)
+	if got := formatTree(*tree); got != "1(2[teacher_of])" {
+		t.Errorf("expected cycle to stop at the root, got %s", got)
+	}
+}
+
+func nodeIDs(nodes []Node) []int {
+	ids := make([]int, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// formatTree renders a tree as "id[relation](child child)".
+func formatTree(n TreeNode) string {
+	s := fmt.Sprint(n.ID)
+	if n.Relation != "" {
+		s += "[" + n.Relation + "]"
+	}
+	if len(n.Children) > 0 {
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = formatTree(c)
+		}
+		s += "(" + strings.Join(parts, " ") + ")"
+	}
+	return s
+}
@@ -0,0 +1,363 @@
+// Package lint checks the content data files against the asset tree.
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/textnorm"
+)
+
+// Severity of a Finding. Errors break the app; warnings are editorial.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Check names, stable so that tooling can filter on them.
+const (
+	CheckIntegrity        = "integrity"
+	CheckMissingImage     = "missing-image"
+	CheckEmptyAssetFolder = "empty-asset-folder"
+	CheckOrphanedAssets   = "orphaned-asset-folder"
+	CheckSlugMismatch     = "slug-mismatch"
+	CheckEmptyDescription = "empty-description"
+)
+
+// assetURLPrefix is the URL prefix under which the assets directory is served.
+const assetURLPrefix = "/assets/"
+
+// Finding is a single problem reported by the linter. File is either a data
+// file name (with ID set) or an asset path relative to the assets directory.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	File     string   `json:"file"`
+	ID       int      `json:"id,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	location := f.File
+	if f.ID != 0 {
+		location = fmt.Sprintf("%s:%d", f.File, f.ID)
+	}
+	return fmt.Sprintf("%-7s %s [%s] %s", f.Severity, location, f.Check, f.Message)
+}
+
+// Report is the result of a lint run.
+type Report struct {
+	Findings []Finding `json:"findings"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+func (r *Report) add(severity Severity, check, file string, id int, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: severity,
+		Check:    check,
+		File:     file,
+		ID:       id,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	if severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+type content struct {
+	authors   []models.Author
+	resources []models.Resource
+	sections  []models.Section
+	items     []models.Item
+}
+
+// Run lints the JSON data files in dataDir against the assets directory
+// (the directory that contains books/). It returns an error only when the
+// data cannot be read at all; content problems are reported as findings.
+func Run(dataDir, assetsDir string) (*Report, error) {
+	data := os.DirFS(dataDir)
+
+	var c content
+	files := []struct {
+		name string
+		dest interface{}
+	}{
+		{"authors.json", &c.authors},
+		{"resources.json", &c.resources},
+		{"sections.json", &c.sections},
+		{"items.json", &c.items},
+	}
+	for _, f := range files {
+		raw, err := fs.ReadFile(data, f.name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.name, err)
+		}
+		if err := json.Unmarshal(raw, f.dest); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f.name, err)
+		}
+	}
+
+	report := &Report{}
+
+	if _, err := store.Load(data); err != nil {
+		var verr *store.ValidationError
+		if !errors.As(err, &verr) {
+			return nil, err
+		}
+		for _, p := range verr.Problems {
+			report.add(SeverityError, CheckIntegrity, p.File, p.ID, "%s", p.Message)
+		}
+	}
+
+	l := &linter{report: report, content: &c, assetsDir: assetsDir, referenced: make(map[string]bool)}
+	l.checkImages()
+	l.checkSlugs()
+	l.checkDescriptions()
+	if err := l.checkAssetFolders(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity == SeverityError
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.ID < b.ID
+	})
+
+	return report, nil
+}
+
+type linter struct {
+	report    *Report
+	content   *content
+	assetsDir string
+
+	// referenced holds every asset folder (relative to assetsDir, slash
+	// separated) that some image URL points into.
+	referenced map[string]bool
+}
+
+func (l *linter) checkImages() {
+	for _, a := range l.content.authors {
+		if a.ImageURL != nil {
+			l.checkImage("authors.json", a.ID, "image_url", *a.ImageURL)
+		}
+	}
+	for _, r := range l.content.resources {
+		if r.CoverImageURL != nil {
+			l.checkImage("resources.json", r.ID, "cover_image_url", *r.CoverImageURL)
+		}
+	}
+	for _, item := range l.content.items {
+		urls := itemkind.ImageURLs(item.Kind, item.Attributes)
+		fields := make([]string, 0, len(urls))
+		for field := range urls {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			l.checkImage("items.json", item.ID, field, urls[field])
+		}
+	}
+}
+
+func (l *linter) checkImage(file string, id int, field, url string) {
+	rel, ok := assetPath(url)
+	if !ok {
+		l.report.add(SeverityError, CheckMissingImage, file, id, "%s %q is not under %s", field, url, assetURLPrefix)
+		return
+	}
+	l.referenced[path.Dir(rel)] = true
+
+	info, err := os.Stat(filepath.Join(l.assetsDir, filepath.FromSlash(rel)))
+	switch {
+	case err != nil:
+		l.report.add(SeverityError, CheckMissingImage, file, id, "%s %s does not exist", field, url)
+	case info.IsDir():
+		l.report.add(SeverityError, CheckMissingImage, file, id, "%s %s is a directory", field, url)
+	}
+}
+
+// checkSlugs compares asset folder names with the slugs of the records they
+// belong to: books/<resource slug>/cover/ and
+// books/<resource slug>/techniques/<item slug>/. A record's slug is its
+// explicit slug or else its slugified title; a folder named after a former
+// slug, as a retitled record keeps, also matches.
+func (l *linter) checkSlugs() {
+	bookByResource := make(map[int]string)
+	for _, r := range l.content.resources {
+		if r.CoverImageURL == nil {
+			continue
+		}
+		book, _, ok := bookFolder(*r.CoverImageURL)
+		if !ok {
+			continue
+		}
+		bookByResource[r.ID] = book
+		if want := effectiveSlug(r.Slug, r.Title); !slugMatches(book, want, r.FormerSlugs) {
+			l.report.add(SeverityWarning, CheckSlugMismatch, "resources.json", r.ID,
+				"asset folder %q does not match the slug of %q (expected %q)", book, r.Title, want)
+		}
+	}
+
+	resourceBySection := make(map[int]int, len(l.content.sections))
+	for _, sec := range l.content.sections {
+		resourceBySection[sec.ID] = sec.ResourceID
+	}
+
+	for _, item := range l.content.items {
+		url := historicalImageURL(item)
+		if url == "" {
+			continue
+		}
+		book, folder, ok := bookFolder(url)
+		if !ok {
+			continue
+		}
+		if want := effectiveSlug(item.Slug, item.Title); !slugMatches(folder, want, item.FormerSlugs) {
+			l.report.add(SeverityWarning, CheckSlugMismatch, "items.json", item.ID,
+				"asset folder %q does not match the slug of %q (expected %q)", folder, item.Title, want)
+		}
+		if expected, ok := bookByResource[resourceBySection[item.SectionID]]; ok && book != expected {
+			l.report.add(SeverityWarning, CheckSlugMismatch, "items.json", item.ID,
+				"image is stored under book %q but the item's resource uses %q", book, expected)
+		}
+	}
+}
+
+// effectiveSlug returns slug, or the slug derived from title when the data
+// leaves it out, as the store does.
+func effectiveSlug(slug, title string) string {
+	if slug != "" {
+		return slug
+	}
+	return textnorm.Slugify(title)
+}
+
+// slugMatches reports whether folder is named after slug or one of former.
+func slugMatches(folder, slug string, former []string) bool {
+	return folder == slug || slices.Contains(former, folder)
+}
+
+func (l *linter) checkDescriptions() {
+	for _, r := range l.content.resources {
+		if strings.TrimSpace(r.Description) == "" {
+			l.report.add(SeverityWarning, CheckEmptyDescription, "resources.json", r.ID, "%q has an empty description", r.Title)
+		}
+	}
+	for _, sec := range l.content.sections {
+		if strings.TrimSpace(sec.Description) == "" {
+			l.report.add(SeverityWarning, CheckEmptyDescription, "sections.json", sec.ID, "%q has an empty description", sec.Title)
+		}
+	}
+	for _, item := range l.content.items {
+		if strings.TrimSpace(item.Description) == "" {
+			l.report.add(SeverityWarning, CheckEmptyDescription, "items.json", item.ID, "%q has an empty description", item.Title)
+		}
+	}
+}
+
+// checkAssetFolders walks books/*/techniques/* looking for folders that hold
+// nothing but a .gitkeep and folders that no item references.
+func (l *linter) checkAssetFolders() error {
+	pattern := filepath.Join(l.assetsDir, "books", "*", "techniques", "*")
+	dirs, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(l.assetsDir, dir)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", dir, err)
+		}
+		onlyGitkeep := true
+		for _, e := range entries {
+			if e.Name() != ".gitkeep" {
+				onlyGitkeep = false
+				break
+			}
+		}
+
+		if onlyGitkeep {
+			l.report.add(SeverityWarning, CheckEmptyAssetFolder, rel, 0, "folder only holds a .gitkeep")
+		}
+		if !l.referenced[rel] {
+			l.report.add(SeverityWarning, CheckOrphanedAssets, rel, 0, "no item references this folder")
+		}
+	}
+
+	return nil
+}
+
+func historicalImageURL(item models.Item) string {
+	attrs, err := itemkind.Decode(item.Kind, item.Attributes)
+	if err != nil {
+		return ""
+	}
+	if t, ok := attrs.(*itemkind.TechniqueAttributes); ok {
+		return t.HistoricalImageURL
+	}
+	return ""
+}
+
+// assetPath converts an /assets/... URL into a clean path relative to the
+// assets directory.
+func assetPath(url string) (string, bool) {
+	if !strings.HasPrefix(url, assetURLPrefix) {
+		return "", false
+	}
+	rel := path.Clean(strings.TrimPrefix(url, assetURLPrefix))
+	if rel == "." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// bookFolder splits /assets/books/<book>/<kind>/<folder>/... and
+// /assets/books/<book>/cover/... URLs into the book and leaf folder names.
+func bookFolder(url string) (book, folder string, ok bool) {
+	rel, ok := assetPath(url)
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.Split(rel, "/")
+	if len(parts) < 3 || parts[0] != "books" {
+		return "", "", false
+	}
+	if len(parts) >= 5 {
+		return parts[1], parts[3], true
+	}
+	return parts[1], "", true
+}
@@ -0,0 +1,130 @@
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/testutil"
+)
+
+func TestRun(t *testing.T) {
+	dataDir := testutil.WriteDataDir(t)
+	assetsDir := t.TempDir()
+
+	cover := "/assets/books/book-a/cover/cover.jpg"
+	resources := testutil.TestResources()
+	resources[0].CoverImageURL = &cover
+	resources[1].Description = " "
+	testutil.WriteDataFile(t, dataDir, "resources.json", resources)
+
+	items := testutil.TestItems()
+	items[0].Attributes = json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/books/book-a/techniques/technique-1/historical.jpg"}`)
+	items[1].Attributes = json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/books/book-a/techniques/old-name/historical.jpg"}`)
+	items[2].Attributes = json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/books/book-b/techniques/technique-3/historical.jpg"}`)
+	// A video poster hosted elsewhere is not an asset.
+	items[3].Kind = "video"
+	items[3].Attributes = json.RawMessage(`{"video_url": "https://video.example.com/4", "duration_seconds": 60, "thumbnail_url": "https://img.example.com/4.jpg"}`)
+	testutil.WriteDataFile(t, dataDir, "items.json", items)
+
+	writeAsset(t, assetsDir, "books/book-a/cover/cover.jpg")
+	writeAsset(t, assetsDir, "books/book-a/techniques/technique-1/historical.jpg")
+	writeAsset(t, assetsDir, "books/book-a/techniques/old-name/.gitkeep")
+	writeAsset(t, assetsDir, "books/book-a/techniques/unused/historical.jpg")
+
+	report, err := Run(dataDir, assetsDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Finding{
+		{Severity: SeverityError, Check: CheckMissingImage, File: "items.json", ID: 2},
+		{Severity: SeverityError, Check: CheckMissingImage, File: "items.json", ID: 3},
+		{Severity: SeverityWarning, Check: CheckEmptyAssetFolder, File: "books/book-a/techniques/old-name"},
+		{Severity: SeverityWarning, Check: CheckOrphanedAssets, File: "books/book-a/techniques/unused"},
+		{Severity: SeverityWarning, Check: CheckSlugMismatch, File: "items.json", ID: 2},
+		{Severity: SeverityWarning, Check: CheckSlugMismatch, File: "items.json", ID: 3},
+		{Severity: SeverityWarning, Check: CheckEmptyDescription, File: "resources.json", ID: 2},
+	}
+
+	if len(report.Findings) != len(expected) {
+		for _, f := range report.Findings {
+			t.Log(f)
+		}
+		t.Fatalf("expected %d findings, got %d", len(expected), len(report.Findings))
+	}
+	for i, want := range expected {
+		got := report.Findings[i]
+		if got.Severity != want.Severity || got.Check != want.Check || got.File != want.File || got.ID != want.ID {
+			t.Errorf("finding %d: expected %s %s %s:%d, got %s", i, want.Severity, want.Check, want.File, want.ID, got)
+		}
+	}
+	if report.Errors != 2 || report.Warnings != 5 {
+		t.Errorf("expected 2 errors and 5 warnings, got %d and %d", report.Errors, report.Warnings)
+	}
+}
+
+func TestRun_ExplicitSlugs(t *testing.T) {
+	dataDir := testutil.WriteDataDir(t)
+	assetsDir := t.TempDir()
+
+	// Book A keeps its folder under an explicit slug. Item 1 has an explicit
+	// slug that differs from its title, and item 2 was retitled and keeps its
+	// old folder, listing the old slug as former.
+	cover := "/assets/books/fechtbuch-a/cover/cover.jpg"
+	resources := testutil.TestResources()
+	resources[0].Slug = "fechtbuch-a"
+	resources[0].CoverImageURL = &cover
+	testutil.WriteDataFile(t, dataDir, "resources.json", resources)
+
+	items := testutil.TestItems()
+	items[0].Slug = "first-ward"
+	items[0].Attributes = json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/books/fechtbuch-a/techniques/first-ward/historical.jpg"}`)
+	items[1].FormerSlugs = []string{"second-ward"}
+	items[1].Attributes = json.RawMessage(`{"instructions": "Step 1", "historical_image_url": "/assets/books/fechtbuch-a/techniques/second-ward/historical.jpg"}`)
+	testutil.WriteDataFile(t, dataDir, "items.json", items)
+
+	writeAsset(t, assetsDir, "books/fechtbuch-a/cover/cover.jpg")
+	writeAsset(t, assetsDir, "books/fechtbuch-a/techniques/first-ward/historical.jpg")
+	writeAsset(t, assetsDir, "books/fechtbuch-a/techniques/second-ward/historical.jpg")
+
+	report, err := Run(dataDir, assetsDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Check == CheckSlugMismatch {
+			t.Errorf("expected folders named after slugs to match, got %s", f)
+		}
+	}
+}
+
+func TestRun_ReportsIntegrityProblems(t *testing.T) {
+	dataDir := testutil.WriteDataDir(t)
+
+	items := append(testutil.TestItems(), models.Item{ID: 7, SectionID: 42, Kind: "technique", Title: "Lost", Description: "Lost", Position: 1})
+	testutil.WriteDataFile(t, dataDir, "items.json", items)
+
+	report, err := Run(dataDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Findings) == 0 || report.Findings[0].Check != CheckIntegrity || report.Findings[0].ID != 7 {
+		t.Errorf("expected an integrity finding for item 7, got %v", report.Findings)
+	}
+}
+
+func writeAsset(t *testing.T, root, rel string) {
+	t.Helper()
+
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
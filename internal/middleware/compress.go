@@ -0,0 +1,418 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings offered by Compress, most preferred first.
+const (
+	EncodingZstd = "zstd"
+	EncodingGzip = "gzip"
+)
+
+// DefaultCompressibleTypes are the media types Compress compresses when
+// CompressOptions.ContentTypes is empty. Images other than SVG, such as the
+// JPEGs under /assets/, are already compressed and are left alone.
+var DefaultCompressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"image/svg+xml",
+	"text/*",
+}
+
+// DefaultCompressMinSize is the smallest body Compress compresses when
+// CompressOptions.MinSize is zero. Smaller bodies gain little and may grow.
+const DefaultCompressMinSize = 1024
+
+// CompressOptions configures Compress.
+type CompressOptions struct {
+	// MinSize is the smallest body, in bytes, worth compressing.
+	MinSize int
+	// ContentTypes lists the media types that may be compressed. An entry
+	// ending in "/*", such as "text/*", matches every subtype.
+	ContentTypes []string
+	// CacheSize bounds, in bytes, the compressed bodies kept for responses
+	// with a strong ETag, so a response that has not changed is compressed
+	// once per encoding rather than on every request. Zero disables it.
+	CacheSize int
+}
+
+// Compress compresses response bodies with zstd or gzip, whichever the
+// client's Accept-Encoding prefers (zstd on a tie). Only 200 responses to GET
+// requests without a Range header are compressed, and only when their media
+// type is allowed, they are not already encoded and they reach MinSize.
+//
+// A compressed response's ETag gets the coding appended, as in "abc-gzip",
+// since it names a different representation; the suffix is stripped from
+// If-None-Match before next sees it.
+func Compress(opts CompressOptions, next http.Handler) http.Handler {
+	if opts.MinSize <= 0 {
+		opts.MinSize = DefaultCompressMinSize
+	}
+	if len(opts.ContentTypes) == 0 {
+		opts.ContentTypes = DefaultCompressibleTypes
+	}
+	var cache *compressedCache
+	if opts.CacheSize > 0 {
+		cache = newCompressedCache(opts.CacheSize)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"))
+
+		revalidated := false
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			stripped, found := stripEncodingSuffixes(inm)
+			if found {
+				r = r.Clone(r.Context())
+				r.Header.Set("If-None-Match", stripped)
+				revalidated = true
+			}
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			opts:           &opts,
+			cache:          cache,
+			encoding:       encoding,
+			revalidated:    revalidated,
+			eligible: encoding != "" && r.Method == http.MethodGet &&
+				r.Header.Get("Range") == "",
+		}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the coding to use from Accept-Encoding values, or
+// "" for none. Codings with q=0 are refused; "*" stands for any coding not
+// listed.
+func negotiateEncoding(values []string) string {
+	q := map[string]float64{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			weight := 1.0
+			if k, val, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+					weight = f
+				}
+			}
+			q[name] = weight
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range []string{EncodingZstd, EncodingGzip} {
+		weight, ok := q[enc]
+		if !ok {
+			weight, ok = q["*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = enc, weight
+		}
+	}
+	return best
+}
+
+// withEncoding appends the coding to an entity tag: "abc" becomes "abc-gzip".
+func withEncoding(etag, encoding string) string {
+	if !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + encoding + `"`
+}
+
+// stripEncodingSuffixes removes the codings withEncoding appends from every
+// tag of an If-None-Match value, and reports whether it found any.
+func stripEncodingSuffixes(inm string) (string, bool) {
+	found := false
+	tags := strings.Split(inm, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for _, enc := range []string{EncodingZstd, EncodingGzip} {
+			if suffix := "-" + enc + `"`; strings.HasSuffix(tag, suffix) {
+				tag = tag[:len(tag)-len(suffix)] + `"`
+				found = true
+				break
+			}
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", "), found
+}
+
+// compressWriter holds a response back until it knows whether to compress
+// it: not at all if it is ineligible, and only once the body reaches MinSize.
+type compressWriter struct {
+	http.ResponseWriter
+	opts        *CompressOptions
+	cache       *compressedCache
+	encoding    string
+	eligible    bool // the request allows compression
+	revalidated bool // If-None-Match named a compressed representation
+
+	status   int
+	decided  bool
+	compress bool
+	cached   bool           // the body was served from the cache
+	buf      []byte         // body held back until it reaches MinSize
+	enc      io.WriteCloser // set once compression has started
+	cacheKey string
+	copy     *bytes.Buffer // compressed body being collected for the cache
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.status != 0 {
+		return
+	}
+	cw.status = code
+	// Without a Content-Type the decision waits for the first Write, whose
+	// bytes can be sniffed as net/http would.
+	if code != http.StatusOK || cw.Header().Get("Content-Type") != "" {
+		cw.decide(nil)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		cw.decide(b)
+	}
+
+	switch {
+	case cw.cached:
+		return len(b), nil
+	case !cw.compress:
+		return cw.ResponseWriter.Write(b)
+	case cw.enc != nil:
+		return cw.enc.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.opts.MinSize {
+		if err := cw.startCompressing(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// decide settles whether the response is compressed and, if not, sends its
+// header. sniff is the start of the body, used when no Content-Type is set.
+func (cw *compressWriter) decide(sniff []byte) {
+	cw.decided = true
+	h := cw.Header()
+
+	ct := h.Get("Content-Type")
+	if ct == "" && sniff != nil {
+		ct = http.DetectContentType(sniff)
+		h.Set("Content-Type", ct)
+	}
+	if cw.status == http.StatusNotModified || cw.typeAllowed(ct) {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	cw.compress = cw.eligible && cw.status == http.StatusOK &&
+		h.Get("Content-Encoding") == "" && cw.typeAllowed(ct)
+	if !cw.compress {
+		if cw.status == http.StatusNotModified && cw.revalidated && cw.encoding != "" {
+			if etag := h.Get("ETag"); etag != "" {
+				h.Set("ETag", withEncoding(etag, cw.encoding))
+			}
+		}
+		cw.ResponseWriter.WriteHeader(cw.status)
+		return
+	}
+
+	if cw.cache == nil {
+		return
+	}
+	if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		cw.cacheKey = cw.encoding + " " + etag
+		if body, ok := cw.cache.get(cw.cacheKey); ok {
+			cw.cached = true
+			cw.setEncodingHeaders()
+			h.Set("Content-Length", strconv.Itoa(len(body)))
+			cw.ResponseWriter.WriteHeader(cw.status)
+			cw.ResponseWriter.Write(body)
+		}
+	}
+}
+
+func (cw *compressWriter) typeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range cw.opts.ContentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (cw *compressWriter) setEncodingHeaders() {
+	h := cw.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", cw.encoding)
+	if etag := h.Get("ETag"); etag != "" {
+		h.Set("ETag", withEncoding(etag, cw.encoding))
+	}
+}
+
+func (cw *compressWriter) startCompressing() error {
+	cw.setEncodingHeaders()
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	var dst io.Writer = cw.ResponseWriter
+	if cw.cacheKey != "" {
+		cw.copy = &bytes.Buffer{}
+		dst = io.MultiWriter(cw.ResponseWriter, cw.copy)
+	}
+	cw.enc = newEncoder(cw.encoding, dst)
+
+	_, err := cw.enc.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// close finishes the response after the handler returns: a body that never
+// reached MinSize is sent as is, and a compressed one is flushed and cached.
+func (cw *compressWriter) close() {
+	if cw.status != 0 && !cw.decided {
+		cw.decide(nil)
+	}
+	switch {
+	case cw.enc != nil:
+		if err := cw.enc.Close(); err != nil {
+			return
+		}
+		if cw.copy != nil {
+			cw.cache.add(cw.cacheKey, cw.copy.Bytes())
+		}
+	case cw.compress && !cw.cached:
+		cw.Header().Set("Content-Length", strconv.Itoa(len(cw.buf)))
+		cw.ResponseWriter.WriteHeader(cw.status)
+		cw.ResponseWriter.Write(cw.buf)
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Encoders are pooled, as a zstd encoder in particular is costly to create.
+var (
+	gzipPool = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}}
+	zstdPool = sync.Pool{New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}}
+)
+
+// pooledEncoder returns its encoder to the pool when closed.
+type pooledEncoder struct {
+	io.WriteCloser
+	release func()
+}
+
+func (p *pooledEncoder) Close() error {
+	err := p.WriteCloser.Close()
+	p.release()
+	return err
+}
+
+func newEncoder(encoding string, dst io.Writer) io.WriteCloser {
+	if encoding == EncodingZstd {
+		z := zstdPool.Get().(*zstd.Encoder)
+		z.Reset(dst)
+		return &pooledEncoder{WriteCloser: z, release: func() { zstdPool.Put(z) }}
+	}
+	g := gzipPool.Get().(*gzip.Writer)
+	g.Reset(dst)
+	return &pooledEncoder{WriteCloser: g, release: func() { gzipPool.Put(g) }}
+}
+
+// compressedCache is a least-recently-used cache of compressed bodies keyed
+// by coding and strong ETag, bounded by the total size of the bodies.
+type compressedCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	body []byte
+}
+
+func newCompressedCache(maxSize int) *compressedCache {
+	return &compressedCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *compressedCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).body, true
+}
+
+func (c *compressedCache) add(key string, body []byte) {
+	if len(body) > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, body: body})
+	c.size += len(body)
+	for c.size > c.maxSize {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= len(entry.body)
+	}
+}
@@ -0,0 +1,291 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"hema-lessons/internal/store"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: ""},
+		{header: "gzip", expected: EncodingGzip},
+		{header: "gzip, deflate, br, zstd", expected: EncodingZstd},
+		{header: "zstd;q=0.5, gzip", expected: EncodingGzip},
+		{header: "gzip;q=0.8, zstd;q=0.8", expected: EncodingZstd},
+		{header: "zstd;q=0, gzip;q=0", expected: ""},
+		{header: "*", expected: EncodingZstd},
+		{header: "*;q=0.5, zstd;q=0", expected: EncodingGzip},
+		{header: "identity", expected: ""},
+		{header: "GZIP", expected: EncodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := negotiateEncoding([]string{tt.header}); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	largeJSON := `{"items":[` + strings.Repeat(`{"title":"Posta di Finestra"},`, 100) + `{}]}`
+	jpeg := string(bytes.Repeat([]byte{0xff, 0xd8, 0xff, 0xe0}, 1000))
+
+	h := Compress(CompressOptions{MinSize: 256}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok":true}`)
+		case "/assets/cover.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			io.WriteString(w, jpeg)
+		case "/encoded":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, largeJSON)
+		case "/error":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, largeJSON)
+		case "/sniffed":
+			io.WriteString(w, largeJSON)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			// Write in pieces to cross MinSize part-way through.
+			io.WriteString(w, largeJSON[:100])
+			io.WriteString(w, largeJSON[100:])
+		}
+	}))
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		header           http.Header
+		expectedEncoding string
+		expectedBody     string
+		expectedVary     bool
+	}{
+		{
+			name:             "zstd preferred",
+			path:             "/api/items",
+			header:           http.Header{"Accept-Encoding": {"gzip, zstd"}},
+			expectedEncoding: EncodingZstd,
+			expectedBody:     largeJSON,
+			expectedVary:     true,
+		},
+		{
+			name:             "gzip",
+			path:             "/api/items",
+			header:           http.Header{"Accept-Encoding": {"gzip"}},
+			expectedEncoding: EncodingGzip,
+			expectedBody:     largeJSON,
+			expectedVary:     true,
+		},
+		{
+			name:         "no Accept-Encoding",
+			path:         "/api/items",
+			expectedBody: largeJSON,
+			expectedVary: true,
+		},
+		{
+			name:         "below MinSize",
+			path:         "/small",
+			header:       http.Header{"Accept-Encoding": {"gzip"}},
+			expectedBody: `{"ok":true}`,
+			expectedVary: true,
+		},
+		{
+			name:         "JPEG asset",
+			path:         "/assets/cover.jpg",
+			header:       http.Header{"Accept-Encoding": {"gzip, zstd"}},
+			expectedBody: jpeg,
+		},
+		{
+			name:             "already encoded",
+			path:             "/encoded",
+			header:           http.Header{"Accept-Encoding": {"gzip"}},
+			expectedEncoding: "br", // left as the handler wrote it
+			expectedBody:     largeJSON,
+			expectedVary:     true,
+		},
+		{
+			name:         "error status",
+			path:         "/error",
+			header:       http.Header{"Accept-Encoding": {"gzip"}},
+			expectedBody: largeJSON,
+			expectedVary: true,
+		},
+		{
+			name:         "range request",
+			path:         "/api/items",
+			header:       http.Header{"Accept-Encoding": {"gzip"}, "Range": {"bytes=0-99"}},
+			expectedBody: largeJSON,
+			expectedVary: true,
+		},
+		{
+			name:             "sniffed content type",
+			path:             "/sniffed",
+			header:           http.Header{"Accept-Encoding": {"gzip"}},
+			expectedEncoding: EncodingGzip,
+			expectedBody:     largeJSON,
+			expectedVary:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.expectedEncoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.expectedEncoding, got)
+			}
+			if got := decode(t, tt.expectedEncoding, w.Body.Bytes()); got != tt.expectedBody {
+				t.Errorf("unexpected body of %d bytes", len(got))
+			}
+			if vary := w.Header().Get("Vary") == "Accept-Encoding"; vary != tt.expectedVary {
+				t.Errorf("expected Vary %v, got %q", tt.expectedVary, w.Header().Get("Vary"))
+			}
+			if tt.expectedEncoding != "" && tt.expectedEncoding != "br" && w.Header().Get("Content-Length") != "" {
+				t.Error("expected no Content-Length on a compressed response")
+			}
+		})
+	}
+}
+
+func TestCompress_WithConditionalGET(t *testing.T) {
+	body := strings.Repeat("technique ", 500)
+	versions := &fixedVersion{version: store.Version{Hash: "abc", LoadedAt: time.Now()}}
+
+	var seenINM string
+	runs := 0
+	h := Compress(CompressOptions{CacheSize: 1 << 20}, ConditionalGET(versions, "public, no-cache", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		seenINM = r.Header.Get("If-None-Match")
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+	})))
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/items/1", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	plain := get(nil)
+	etag := plain.Header().Get("ETag")
+
+	first := get(http.Header{"Accept-Encoding": {"gzip"}})
+	gzipETag := first.Header().Get("ETag")
+	if gzipETag != withEncoding(etag, EncodingGzip) {
+		t.Fatalf("expected ETag %q, got %q", withEncoding(etag, EncodingGzip), gzipETag)
+	}
+
+	// The second response is served from the cache of compressed bodies.
+	second := get(http.Header{"Accept-Encoding": {"gzip"}})
+	if !bytes.Equal(first.Body.Bytes(), second.Body.Bytes()) {
+		t.Error("expected the cached body to match the first")
+	}
+	if got := decode(t, EncodingGzip, second.Body.Bytes()); got != body {
+		t.Errorf("unexpected cached body of %d bytes", len(got))
+	}
+	if second.Header().Get("Content-Length") == "" {
+		t.Error("expected Content-Length on a cached body")
+	}
+
+	runs = 0
+	revalidated := get(http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {gzipETag}})
+	if revalidated.Code != http.StatusNotModified {
+		t.Fatalf("expected status code %d, got %d", http.StatusNotModified, revalidated.Code)
+	}
+	if runs != 0 {
+		t.Error("expected the handler not to run on a 304")
+	}
+	if got := revalidated.Header().Get("ETag"); got != gzipETag {
+		t.Errorf("expected ETag %q on 304, got %q", gzipETag, got)
+	}
+
+	get(http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {`"stale-gzip"`}})
+	if seenINM != `"stale"` {
+		t.Errorf("expected the coding suffix to be stripped, handler saw %q", seenINM)
+	}
+}
+
+func TestCompressedCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCompressedCache(10)
+	c.add("a", []byte("1234"))
+	c.add("b", []byte("1234"))
+	c.get("a")
+	c.add("c", []byte("1234"))
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+
+	c.add("huge", make([]byte, 11))
+	if _, ok := c.get("huge"); ok {
+		t.Error("expected a body larger than the cache not to be kept")
+	}
+}
+
+func decode(t *testing.T, encoding string, b []byte) string {
+	t.Helper()
+
+	var r io.Reader
+	switch encoding {
+	case EncodingGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("failed to read gzip: %v", err)
+		}
+		r = gz
+	case EncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("failed to read zstd: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return string(b)
+	}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", encoding, err)
+	}
+	return string(out)
+}
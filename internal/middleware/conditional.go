@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"hema-lessons/internal/store"
+)
+
+// Versioner reports the version of the content behind responses.
+type Versioner interface {
+	Version() (store.Version, error)
+}
+
+// ConditionalGET lets clients revalidate GET and HEAD responses cheaply. A
+// 200 response gets a strong ETag derived from the content version and the
+// request URL, a Last-Modified time and the given Cache-Control header. A
+// request whose If-None-Match lists the current ETag, or failing that whose
+// If-Modified-Since is not before the content was loaded, is answered with
+// 304 Not Modified. Only a listed ETag proves the URL resolves, since ETags
+// are only sent with 200 responses, so that request skips next; for
+// If-None-Match: * and If-Modified-Since next still runs, and only a 200
+// becomes a 304, so a URL that resolves to an error or a redirect keeps it.
+//
+// Responses must depend only on the content and the request URL.
+func ConditionalGET(v Versioner, cacheControl string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, err := v.Version()
+		if err != nil {
+			slog.Error("failed to get content version", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		etag := contentETag(version.Hash, r.URL.RequestURI())
+		modified := version.LoadedAt.UTC().Truncate(time.Second)
+		setHeaders := func(h http.Header) {
+			h.Set("ETag", etag)
+			h.Set("Last-Modified", modified.Format(http.TimeFormat))
+			h.Set("Cache-Control", cacheControl)
+		}
+
+		match, listed := notModified(r, etag, modified)
+		if listed {
+			setHeaders(w.Header())
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		next.ServeHTTP(&conditionalWriter{
+			ResponseWriter: w,
+			setHeaders:     setHeaders,
+			notModified:    match,
+		}, r)
+	})
+}
+
+// contentETag returns a strong ETag for the representation of uri under the
+// content with the given hash.
+func contentETag(hash, uri string) string {
+	sum := sha256.Sum256([]byte(hash + "\x00" + uri))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates If-None-Match and, only when it is absent,
+// If-Modified-Since, as RFC 9110 section 13.2.2 orders them. listed reports
+// that If-None-Match names etag itself rather than matching through *.
+func notModified(r *http.Request, etag string, modified time.Time) (match, listed bool) {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			// If-None-Match uses the weak comparison.
+			if strings.TrimPrefix(tag, "W/") == etag {
+				return true, true
+			}
+			if tag == "*" {
+				match = true
+			}
+		}
+		return match, false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modified.After(t), false
+	}
+	return false, false
+}
+
+// conditionalWriter adds the validator and caching headers to a 200 response
+// when its header is written, and turns it into a bodiless 304 when the
+// request's validators match. Errors and redirects are left uncached and
+// unchanged.
+type conditionalWriter struct {
+	http.ResponseWriter
+	setHeaders  func(http.Header)
+	notModified bool
+	wroteHeader bool
+	discard     bool
+}
+
+func (cw *conditionalWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if code == http.StatusOK {
+			cw.setHeaders(cw.Header())
+			if cw.notModified {
+				cw.Header().Del("Content-Length")
+				code = http.StatusNotModified
+				cw.discard = true
+			}
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *conditionalWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.discard {
+		return len(b), nil
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *conditionalWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hema-lessons/internal/store"
+)
+
+type fixedVersion struct {
+	version store.Version
+	err     error
+}
+
+func (f *fixedVersion) Version() (store.Version, error) {
+	return f.version, f.err
+}
+
+func TestConditionalGET(t *testing.T) {
+	loaded := time.Date(2026, 10, 17, 9, 30, 15, 500, time.UTC)
+	versions := &fixedVersion{version: store.Version{Hash: "abc", LoadedAt: loaded}}
+
+	calls := 0
+	h := ConditionalGET(versions, "public, no-cache", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		case "/renamed":
+			http.Redirect(w, r, "/api/items/1", http.StatusMovedPermanently)
+			return
+		}
+		io.WriteString(w, "content")
+	}))
+
+	serve := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	first := serve(http.MethodGet, "/api/items/1", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != "content" {
+		t.Fatalf("expected 200 with content, got %d %q", first.Code, first.Body.String())
+	}
+	if len(etag) < 3 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		t.Fatalf("expected a strong ETag, got %q", etag)
+	}
+	if got := first.Header().Get("Last-Modified"); got != "Sat, 17 Oct 2026 09:30:15 GMT" {
+		t.Errorf("unexpected Last-Modified %q", got)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "public, no-cache" {
+		t.Errorf("unexpected Cache-Control %q", got)
+	}
+
+	if other := serve(http.MethodGet, "/api/items/2", nil).Header().Get("ETag"); other == etag {
+		t.Error("expected different URLs to get different ETags")
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		target             string
+		header             http.Header
+		expectedStatusCode int
+		expectedBody       string
+		expectHandlerSkip  bool
+	}{
+		{
+			name:               "matching If-None-Match",
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {etag}},
+			expectedStatusCode: http.StatusNotModified,
+			expectHandlerSkip:  true,
+		},
+		{
+			name:               "matching weak tag in a list",
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {`"other", W/` + etag}},
+			expectedStatusCode: http.StatusNotModified,
+			expectHandlerSkip:  true,
+		},
+		{
+			name:               "wildcard If-None-Match",
+			method:             http.MethodHead,
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {"*"}},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name:               "stale If-None-Match",
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {`"stale"`}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "content",
+		},
+		{
+			name:               "If-None-Match takes precedence over If-Modified-Since",
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {`"stale"`}, "If-Modified-Since": {"Sat, 17 Oct 2026 09:30:15 GMT"}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "content",
+		},
+		{
+			name:               "If-Modified-Since at load time",
+			target:             "/api/items/1",
+			header:             http.Header{"If-Modified-Since": {"Sat, 17 Oct 2026 09:30:15 GMT"}},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name:               "If-Modified-Since before load time",
+			target:             "/api/items/1",
+			header:             http.Header{"If-Modified-Since": {"Sat, 17 Oct 2026 09:30:14 GMT"}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "content",
+		},
+		{
+			name:               "wildcard If-None-Match on a missing URL",
+			target:             "/missing",
+			header:             http.Header{"If-None-Match": {"*"}},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "404 page not found\n",
+		},
+		{
+			name:               "If-Modified-Since on a missing URL",
+			target:             "/missing",
+			header:             http.Header{"If-Modified-Since": {"Sat, 17 Oct 2026 09:30:15 GMT"}},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "404 page not found\n",
+		},
+		{
+			name:               "wildcard If-None-Match on a redirect",
+			method:             http.MethodHead,
+			target:             "/renamed",
+			header:             http.Header{"If-None-Match": {"*"}},
+			expectedStatusCode: http.StatusMovedPermanently,
+		},
+		{
+			name:               "If-Modified-Since on a redirect",
+			target:             "/renamed",
+			header:             http.Header{"If-Modified-Since": {"Sat, 17 Oct 2026 09:30:15 GMT"}},
+			expectedStatusCode: http.StatusMovedPermanently,
+		},
+		{
+			name:               "other methods pass through",
+			method:             http.MethodPost,
+			target:             "/api/items/1",
+			header:             http.Header{"If-None-Match": {etag}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			calls = 0
+
+			w := serve(method, tt.target, tt.header)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, w.Code)
+			}
+			if skipped := calls == 0; skipped != tt.expectHandlerSkip {
+				t.Errorf("expected handler skipped %v, got %v", tt.expectHandlerSkip, skipped)
+			}
+			if tt.expectedStatusCode != http.StatusMovedPermanently && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if w.Code == http.StatusNotModified {
+				if w.Body.Len() != 0 {
+					t.Errorf("expected an empty 304 body, got %q", w.Body.String())
+				}
+				if got := w.Header().Get("ETag"); got != etag {
+					t.Errorf("expected ETag %q on 304, got %q", etag, got)
+				}
+			} else if w.Code != http.StatusOK {
+				if got := w.Header().Get("ETag"); got != "" {
+					t.Errorf("expected no ETag on %d, got %q", w.Code, got)
+				}
+			}
+		})
+	}
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		w := serve(http.MethodGet, "/missing", nil)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+		for _, header := range []string{"ETag", "Last-Modified", "Cache-Control"} {
+			if got := w.Header().Get(header); got != "" {
+				t.Errorf("expected no %s on an error, got %q", header, got)
+			}
+		}
+	})
+
+	t.Run("new content changes the ETag", func(t *testing.T) {
+		versions.version = store.Version{Hash: "def", LoadedAt: loaded.Add(time.Minute)}
+		defer func() { versions.version = store.Version{Hash: "abc", LoadedAt: loaded} }()
+
+		w := serve(http.MethodGet, "/api/items/1", http.Header{"If-None-Match": {etag}})
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Header().Get("ETag") == etag {
+			t.Error("expected a new ETag")
+		}
+	})
+
+	t.Run("version failure serves uncached", func(t *testing.T) {
+		versions.err = errors.New("backend unavailable")
+		defer func() { versions.err = nil }()
+
+		w := serve(http.MethodGet, "/api/items/1", http.Header{"If-None-Match": {etag}})
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if got := w.Header().Get("ETag"); got != "" {
+			t.Errorf("expected no ETag, got %q", got)
+		}
+	})
+}
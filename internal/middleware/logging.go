@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"hema-lessons/internal/requestid"
 )
 
 // responseWriter wraps http.ResponseWriter to capture the status code.
@@ -48,6 +50,7 @@ func RequestLogger(next http.Handler) http.Handler {
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", wrapped.statusCode,
+			"request_id", requestid.FromContext(r.Context()),
 			"duration_ms", duration.Milliseconds(),
 			"remote_addr", r.RemoteAddr,
 			"user_agent", r.UserAgent(),
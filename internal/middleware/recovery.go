@@ -6,6 +6,9 @@ import (
 	"runtime/debug"
 
 	"github.com/getsentry/sentry-go"
+
+	"hema-lessons/internal/problem"
+	"hema-lessons/internal/requestid"
 )
 
 // Recovery recovers from panics and reports them to Sentry.
@@ -21,6 +24,7 @@ func Recovery(next http.Handler) http.Handler {
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method,
+					"request_id", requestid.FromContext(r.Context()),
 					"stack", string(stack),
 				)
 
@@ -32,7 +36,7 @@ func Recovery(next http.Handler) http.Handler {
 				}
 
 				// Return 500 Internal Server Error
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				problem.Internal(w, r, "internal server error")
 			}
 		}()
 
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"hema-lessons/internal/requestid"
+)
+
+// RequestID gives each request an ID, reusing a valid X-Request-ID header
+// from the client, stores it in the request context and echoes it in the
+// response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -1,10 +1,39 @@
 package models
 
+// Author relation types. Every relation points from the earlier master to the
+// later one, so following relations walks forward through a tradition.
+const (
+	// RelationTeacherOf: the author taught the related author.
+	RelationTeacherOf = "teacher_of"
+	// RelationGlossedBy: the related author wrote a gloss on the author's teaching.
+	RelationGlossedBy = "glossed_by"
+	// RelationTradition: the related author continues the author's tradition.
+	RelationTradition = "tradition"
+)
+
 type Author struct {
-	ID        int     `json:"id"`
-	Name      string  `json:"name"`
-	Bio       string  `json:"bio"`
-	BirthYear *int    `json:"birth_year,omitempty"`
-	DeathYear *int    `json:"death_year,omitempty"`
-	ImageURL  *string `json:"image_url,omitempty"`
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Bio       string           `json:"bio"`
+	BirthYear *int             `json:"birth_year,omitempty"`
+	DeathYear *int             `json:"death_year,omitempty"`
+	ImageURL  *string          `json:"image_url,omitempty"`
+	Image     *Image           `json:"image,omitempty"`
+	Relations []AuthorRelation `json:"relations,omitempty"`
+}
+
+// AuthorRelation links an author to a later author.
+type AuthorRelation struct {
+	Type     string `json:"type"`
+	AuthorID int    `json:"author_id"`
+	Note     string `json:"note,omitempty"`
+}
+
+// IsValidAuthorRelation reports whether t is a known relation type.
+func IsValidAuthorRelation(t string) bool {
+	switch t {
+	case RelationTeacherOf, RelationGlossedBy, RelationTradition:
+		return true
+	}
+	return false
 }
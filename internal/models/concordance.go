@@ -0,0 +1,38 @@
+package models
+
+// Concordance link types. Same and variant links are symmetric; counter and
+// prerequisite links read from ItemID to RelatedItemID.
+const (
+	// ConcordanceSame: both items teach the same technique.
+	ConcordanceSame = "same"
+	// ConcordanceVariant: the items teach variations of one technique.
+	ConcordanceVariant = "variant"
+	// ConcordanceCounter: the item counters the related item.
+	ConcordanceCounter = "counter"
+	// ConcordancePrerequisite: the item should be learned before the related item.
+	ConcordancePrerequisite = "prerequisite"
+)
+
+// ConcordanceLink relates an item to another item, usually one in another
+// resource.
+type ConcordanceLink struct {
+	ItemID        int    `json:"item_id"`
+	RelatedItemID int    `json:"related_item_id"`
+	Type          string `json:"type"`
+	Note          string `json:"note,omitempty"`
+}
+
+// IsValidConcordanceType reports whether t is a known concordance link type.
+func IsValidConcordanceType(t string) bool {
+	switch t {
+	case ConcordanceSame, ConcordanceVariant, ConcordanceCounter, ConcordancePrerequisite:
+		return true
+	}
+	return false
+}
+
+// IsSymmetricConcordanceType reports whether links of type t read the same
+// in both directions.
+func IsSymmetricConcordanceType(t string) bool {
+	return t == ConcordanceSame || t == ConcordanceVariant
+}
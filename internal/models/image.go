@@ -0,0 +1,15 @@
+package models
+
+// Image is an image served from /assets/, with a srcset of resized variants
+// for responsive clients. When the image file was found at load time its
+// size and placeholders are included, so clients can lay it out and show a
+// preview before it downloads.
+type Image struct {
+	URL           string  `json:"url"`
+	Srcset        string  `json:"srcset,omitempty"`
+	Width         int     `json:"width,omitempty"`
+	Height        int     `json:"height,omitempty"`
+	AspectRatio   float64 `json:"aspect_ratio,omitempty"`
+	BlurHash      string  `json:"blurhash,omitempty"`
+	DominantColor string  `json:"dominant_color,omitempty"`
+}
@@ -2,12 +2,47 @@ package models
 
 import "encoding/json"
 
+// Item relation types. Each relation reads from the item that holds it to the
+// related item.
+const (
+	// ItemRelationCounters: the item counters the related item.
+	ItemRelationCounters = "counters"
+	// ItemRelationIsCounteredBy: the related item counters the item.
+	ItemRelationIsCounteredBy = "is_countered_by"
+	// ItemRelationFollowUp: the related item follows on from the item.
+	ItemRelationFollowUp = "follow_up"
+	// ItemRelationIsRemedyOf: the item is the remedy against the related item.
+	ItemRelationIsRemedyOf = "is_remedy_of"
+)
+
 type Item struct {
 	ID          int             `json:"id"`
 	SectionID   int             `json:"section_id"`
 	Kind        string          `json:"kind"`
 	Title       string          `json:"title"`
+	Slug        string          `json:"slug"`
 	Description string          `json:"description"`
 	Position    int             `json:"position"`
 	Attributes  json.RawMessage `json:"attributes,omitempty"`
+	// Images holds the images among the attributes, keyed by attribute name.
+	Images      map[string]Image `json:"images,omitempty"`
+	Tags        *Tags            `json:"tags,omitempty"`
+	Relations   []ItemRelation   `json:"relations,omitempty"`
+	FormerSlugs []string         `json:"former_slugs,omitempty"`
+}
+
+// ItemRelation is a directed edge from an item to another item.
+type ItemRelation struct {
+	Type   string `json:"type"`
+	ItemID int    `json:"item_id"`
+	Note   string `json:"note,omitempty"`
+}
+
+// IsValidItemRelation reports whether t is a known item relation type.
+func IsValidItemRelation(t string) bool {
+	switch t {
+	case ItemRelationCounters, ItemRelationIsCounteredBy, ItemRelationFollowUp, ItemRelationIsRemedyOf:
+		return true
+	}
+	return false
 }
@@ -1,11 +1,14 @@
 package models
 
 type Section struct {
-	ID         int    `json:"id"`
-	ResourceID int    `json:"resource_id"`
-	ParentID    *int   `json:"parent_id,omitempty"`
-	Kind        string `json:"kind"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Position    int    `json:"position"`
-}
\ No newline at end of file
+	ID          int      `json:"id"`
+	ResourceID  int      `json:"resource_id"`
+	ParentID    *int     `json:"parent_id,omitempty"`
+	Kind        string   `json:"kind"`
+	Title       string   `json:"title"`
+	Slug        string   `json:"slug"`
+	Description string   `json:"description"`
+	Position    int      `json:"position"`
+	Tags        *Tags    `json:"tags,omitempty"`
+	FormerSlugs []string `json:"former_slugs,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Tags attaches taxonomy terms to an item or section. Every value is the
+// slug of a term defined in the taxonomy.
+type Tags struct {
+	Weapons []string `json:"weapons,omitempty"`
+	Guards  []string `json:"guards,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+	Armour  string   `json:"armour,omitempty"`
+}
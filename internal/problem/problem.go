@@ -0,0 +1,99 @@
+// Package problem writes API errors as RFC 7807 problem details, served as
+// application/problem+json.
+package problem
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"hema-lessons/internal/requestid"
+)
+
+// ContentType is the media type of a problem details body.
+const ContentType = "application/problem+json"
+
+// Codes are stable, machine-readable names for errors. Clients should match
+// on Code rather than Detail, which is written for people and may change.
+// Not-found errors for a kind of record use "<kind>_not_found", such as
+// resource_not_found; see NotFound.
+const (
+	CodeInvalidParameter = "invalid_parameter"
+	CodeMissingParameter = "missing_parameter"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details object. Code, Param and RequestID
+// are extension members.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	Param     string `json:"param,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends p as the response to r. Type, Title, Instance and RequestID are
+// filled in when left empty; Type defaults to about:blank, as the code already
+// identifies the problem.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = requestid.FromContext(r.Context())
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("failed to encode problem: %v", err)
+	}
+}
+
+// InvalidParam reports a path or query parameter with a bad value.
+func InvalidParam(w http.ResponseWriter, r *http.Request, param, detail string) {
+	Write(w, r, Problem{Status: http.StatusBadRequest, Code: CodeInvalidParameter, Param: param, Detail: detail})
+}
+
+// MissingParam reports a required query parameter that was not given.
+func MissingParam(w http.ResponseWriter, r *http.Request, param, detail string) {
+	Write(w, r, Problem{Status: http.StatusBadRequest, Code: CodeMissingParameter, Param: param, Detail: detail})
+}
+
+// NotFound reports that the record of the given kind, such as "section",
+// does not exist, with code section_not_found.
+func NotFound(w http.ResponseWriter, r *http.Request, kind string) {
+	Write(w, r, Problem{Status: http.StatusNotFound, Code: kind + "_not_found", Detail: kind + " not found"})
+}
+
+// RouteNotFound reports a path that no endpoint serves.
+func RouteNotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Problem{Status: http.StatusNotFound, Code: CodeNotFound, Detail: "no endpoint at " + r.URL.Path})
+}
+
+// MethodNotAllowed reports a method the path does not support. The caller
+// sets the Allow header.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, Problem{Status: http.StatusMethodNotAllowed, Code: CodeMethodNotAllowed, Detail: r.Method + " is not supported here"})
+}
+
+// Internal reports a server-side failure. detail should say what failed
+// without exposing the underlying error, which belongs in the log.
+func Internal(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, Problem{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: detail})
+}
@@ -0,0 +1,100 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hema-lessons/internal/requestid"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(w http.ResponseWriter, r *http.Request)
+		expected Problem
+	}{
+		{
+			name: "invalid parameter",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				InvalidParam(w, r, "depth", "depth must be a positive integer")
+			},
+			expected: Problem{
+				Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "depth must be a positive integer", Instance: "/api/resources/1/tree",
+				Code: CodeInvalidParameter, Param: "depth", RequestID: "req-1",
+			},
+		},
+		{
+			name:  "missing parameter",
+			write: func(w http.ResponseWriter, r *http.Request) { MissingParam(w, r, "q", "missing search query") },
+			expected: Problem{
+				Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "missing search query", Instance: "/api/resources/1/tree",
+				Code: CodeMissingParameter, Param: "q", RequestID: "req-1",
+			},
+		},
+		{
+			name:  "record not found",
+			write: func(w http.ResponseWriter, r *http.Request) { NotFound(w, r, "resource") },
+			expected: Problem{
+				Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound,
+				Detail: "resource not found", Instance: "/api/resources/1/tree",
+				Code: "resource_not_found", RequestID: "req-1",
+			},
+		},
+		{
+			name:  "internal error",
+			write: func(w http.ResponseWriter, r *http.Request) { Internal(w, r, "failed to get resource tree") },
+			expected: Problem{
+				Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "failed to get resource tree", Instance: "/api/resources/1/tree",
+				Code: CodeInternal, RequestID: "req-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/resources/1/tree", nil)
+			req = req.WithContext(requestid.NewContext(req.Context(), "req-1"))
+			w := httptest.NewRecorder()
+
+			tt.write(w, req)
+
+			if w.Code != tt.expected.Status {
+				t.Errorf("expected status code %d, got %d", tt.expected.Status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != ContentType {
+				t.Errorf("expected Content-Type %q, got %q", ContentType, ct)
+			}
+
+			var got Problem
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWrite_WithoutRequestID(t *testing.T) {
+	w := httptest.NewRecorder()
+	RouteNotFound(w, httptest.NewRequest(http.MethodGet, "/api/widgets", nil))
+
+	var got map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if got["code"] != CodeNotFound {
+		t.Errorf("expected code %q, got %v", CodeNotFound, got["code"])
+	}
+	for _, key := range []string{"request_id", "param"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("expected %s to be omitted, got %v", key, got[key])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package requestid carries an ID for each request through its context, so
+// logs and error responses can name the request they belong to.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the request and response header holding the ID.
+const Header = "X-Request-ID"
+
+// maxLen bounds IDs accepted from clients.
+const maxLen = 128
+
+type contextKey struct{}
+
+// New returns a random 32-character hex ID.
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("requestid: " + err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an ID sent by a client can be reused: at most 128
+// printable ASCII characters without spaces.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID carried by ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,107 @@
+// Package router serves a declarative route table on top of http.ServeMux
+// path patterns, answering HEAD, OPTIONS and unsupported methods the same way
+// for every path.
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"hema-lessons/internal/problem"
+)
+
+// Route maps a method and a ServeMux path pattern, such as
+// /api/items/{id}/graph, to a handler. Handlers read wildcards with
+// r.PathValue.
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
+
+// New returns a handler that serves routes. A path that has a GET route also
+// answers HEAD, every path answers OPTIONS with 204 and an Allow header, and
+// a path requested with any other method it has no route for gets 405 with
+// the same Allow header. Paths without wildcards also match with a trailing
+// slash. Requests matching no path go to notFound, or problem.RouteNotFound
+// when it is nil. Errors are written as problem details.
+//
+// New panics if two routes share a method and path, or if ServeMux rejects a
+// pattern, since either is a mistake in the route table.
+func New(routes []Route, notFound http.HandlerFunc) http.Handler {
+	var paths []string
+	byPath := make(map[string]methods)
+	for _, rt := range routes {
+		m, ok := byPath[rt.Path]
+		if !ok {
+			m = make(methods)
+			byPath[rt.Path] = m
+			paths = append(paths, rt.Path)
+		}
+		if _, dup := m[rt.Method]; dup {
+			panic(fmt.Sprintf("router: duplicate route %s %s", rt.Method, rt.Path))
+		}
+		m[rt.Method] = rt.Handler
+	}
+
+	mux := http.NewServeMux()
+	for _, path := range paths {
+		h := byPath[path].handler()
+		mux.Handle(path, h)
+		if !strings.HasSuffix(path, "/") && !strings.Contains(path, "{") {
+			mux.Handle(path+"/{$}", h)
+		}
+	}
+	if _, ok := byPath["/"]; !ok {
+		if notFound == nil {
+			notFound = problem.RouteNotFound
+		}
+		mux.Handle("/", notFound)
+	}
+	return mux
+}
+
+// methods holds the handlers of one path, keyed by method.
+type methods map[string]http.HandlerFunc
+
+func (m methods) handler() http.Handler {
+	allow := m.allow()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := m[r.Method]
+		if !ok && r.Method == http.MethodHead {
+			// The server discards the body written for a HEAD request.
+			h, ok = m[http.MethodGet]
+		}
+		if ok {
+			h(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", allow)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		problem.MethodNotAllowed(w, r)
+	})
+}
+
+// allow lists the methods of the path for an Allow header, such as
+// "GET, HEAD, OPTIONS".
+func (m methods) allow() string {
+	set := map[string]bool{http.MethodOptions: true}
+	for method := range m {
+		set[method] = true
+		if method == http.MethodGet {
+			set[http.MethodHead] = true
+		}
+	}
+	list := make([]string, 0, len(set))
+	for method := range set {
+		list = append(list, method)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
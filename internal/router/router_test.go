@@ -0,0 +1,183 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testRoutes() []Route {
+	write := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		}
+	}
+	return []Route{
+		{Method: http.MethodGet, Path: "/api/items", Handler: write("list")},
+		{Method: http.MethodPost, Path: "/api/items", Handler: write("create")},
+		{Method: http.MethodGet, Path: "/api/items/{id}", Handler: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "item "+r.PathValue("id"))
+		}},
+		{Method: http.MethodGet, Path: "/api/items/{id}/graph", Handler: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "graph "+r.PathValue("id"))
+		}},
+		{Method: http.MethodGet, Path: "/api/items/{resource}/{slug}", Handler: func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "slug "+r.PathValue("resource")+"/"+r.PathValue("slug"))
+		}},
+		{Method: http.MethodDelete, Path: "/api/tags/{name}", Handler: write("delete")},
+	}
+}
+
+func TestRouter(t *testing.T) {
+	h := New(testRoutes(), func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nothing here", http.StatusNotFound)
+	})
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+		expectedAllow      string
+	}{
+		{
+			name:               "fixed path",
+			method:             http.MethodGet,
+			path:               "/api/items",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "list",
+		},
+		{
+			name:               "fixed path with trailing slash",
+			method:             http.MethodGet,
+			path:               "/api/items/",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "list",
+		},
+		{
+			name:               "second method of a path",
+			method:             http.MethodPost,
+			path:               "/api/items",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "create",
+		},
+		{
+			name:               "path value",
+			method:             http.MethodGet,
+			path:               "/api/items/7",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "item 7",
+		},
+		{
+			name:               "literal segment wins over a wildcard",
+			method:             http.MethodGet,
+			path:               "/api/items/7/graph",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "graph 7",
+		},
+		{
+			name:               "two wildcards",
+			method:             http.MethodGet,
+			path:               "/api/items/book-a/basic-move",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "slug book-a/basic-move",
+		},
+		{
+			name:               "HEAD is served by GET without a body",
+			method:             http.MethodHead,
+			path:               "/api/items/7",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "OPTIONS lists methods",
+			method:             http.MethodOptions,
+			path:               "/api/items",
+			expectedStatusCode: http.StatusNoContent,
+			expectedAllow:      "GET, HEAD, OPTIONS, POST",
+		},
+		{
+			name:               "wrong method",
+			method:             http.MethodPut,
+			path:               "/api/items/7",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedBody: `{"type":"about:blank","title":"Method Not Allowed","status":405,` +
+				`"detail":"PUT is not supported here","instance":"/api/items/7","code":"method_not_allowed"}` + "\n",
+			expectedAllow: "GET, HEAD, OPTIONS",
+		},
+		{
+			name:               "HEAD without a GET route",
+			method:             http.MethodHead,
+			path:               "/api/tags/longsword",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedAllow:      "DELETE, OPTIONS",
+		},
+		{
+			name:               "unknown path",
+			method:             http.MethodGet,
+			path:               "/api/items/7/graph/extra",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "nothing here\n",
+		},
+		{
+			name:               "trailing slash after a wildcard",
+			method:             http.MethodGet,
+			path:               "/api/items/7/graph/",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "nothing here\n",
+		},
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Go through a real server so HEAD bodies are dropped as in
+			// production.
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, resp.StatusCode)
+			}
+			if string(body) != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+			if got := resp.Header.Get("Allow"); got != tt.expectedAllow {
+				t.Errorf("expected Allow %q, got %q", tt.expectedAllow, got)
+			}
+		})
+	}
+}
+
+func TestNew_DefaultNotFound(t *testing.T) {
+	h := New(testRoutes(), nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestNew_DuplicateRoutePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a duplicate route")
+		}
+	}()
+
+	routes := append(testRoutes(), Route{Method: http.MethodGet, Path: "/api/items/{id}", Handler: http.NotFound})
+	New(routes, nil)
+}
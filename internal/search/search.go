@@ -0,0 +1,175 @@
+// Package search is a small in-memory inverted index over content text. Text
+// is folded with textnorm.Fold, so queries match regardless of case and of
+// Italian or German diacritics.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"hema-lessons/internal/textnorm"
+)
+
+// Field is a piece of text belonging to a document. Matches in fields with a
+// higher Weight rank higher; titles typically outweigh descriptions.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Document is something that can be found: a resource, section or item.
+// Type and ID are returned unchanged in each Result.
+type Document struct {
+	Type   string
+	ID     int
+	Fields []Field
+}
+
+// Result is a ranked match.
+type Result struct {
+	Type  string
+	ID    int
+	Score float64
+}
+
+type posting struct {
+	doc    int     // index into Index.docs
+	weight float64 // sum of field weights over every occurrence of the term
+}
+
+// Index is an immutable inverted index built once from a set of documents.
+type Index struct {
+	docs      []Document
+	titles    []string // folded text of each document's first field
+	postings  map[string][]posting
+	typeOrder map[string]int
+}
+
+// NewIndex indexes docs. The first field of each document is treated as its
+// title for phrase matching. typeOrder lists document types in the order
+// they should appear when scores tie; unlisted types sort last.
+func NewIndex(docs []Document, typeOrder ...string) *Index {
+	ix := &Index{
+		docs:      docs,
+		titles:    make([]string, len(docs)),
+		postings:  make(map[string][]posting),
+		typeOrder: make(map[string]int, len(typeOrder)),
+	}
+	for i, t := range typeOrder {
+		ix.typeOrder[t] = i
+	}
+
+	for i, d := range docs {
+		weights := make(map[string]float64)
+		for j, f := range d.Fields {
+			if j == 0 {
+				ix.titles[i] = strings.Join(Tokenize(f.Text), " ")
+			}
+			for _, term := range Tokenize(f.Text) {
+				weights[term] += f.Weight
+			}
+		}
+		for term, w := range weights {
+			ix.postings[term] = append(ix.postings[term], posting{doc: i, weight: w})
+		}
+	}
+
+	return ix
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Search returns the documents that contain every term of query, best first.
+// A document's score is the sum over query terms of the term's weighted
+// frequency times its inverse document frequency, plus a bonus when the
+// whole query appears as a phrase in the title. Ties are broken by type
+// order and then by ID, so results are stable.
+func (ix *Index) Search(query string) []Result {
+	terms := unique(Tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for i, term := range terms {
+		postings := ix.postings[term]
+		if len(postings) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + float64(len(ix.docs))/float64(len(postings)))
+
+		next := make(map[int]float64, len(postings))
+		for _, p := range postings {
+			if score, ok := scores[p.doc]; ok || i == 0 {
+				next[p.doc] = score + p.weight*idf
+			}
+		}
+		scores = next
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	phrase := strings.Join(Tokenize(query), " ")
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		title := ix.titles[doc]
+		switch {
+		case title == phrase:
+			score *= 2
+		case strings.HasPrefix(title, phrase+" "):
+			score *= 1.5
+		case strings.Contains(" "+title+" ", " "+phrase+" "):
+			score *= 1.25
+		}
+		results = append(results, Result{
+			Type:  ix.docs[doc].Type,
+			ID:    ix.docs[doc].ID,
+			Score: math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if ra, rb := ix.rank(a.Type), ix.rank(b.Type); ra != rb {
+			return ra < rb
+		}
+		return a.ID < b.ID
+	})
+
+	return results
+}
+
+func (ix *Index) rank(docType string) int {
+	if r, ok := ix.typeOrder[docType]; ok {
+		return r
+	}
+	return len(ix.typeOrder)
+}
+
+// Tokenize folds s and splits it into words made of letters and digits.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(textnorm.Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testIndex() *Index {
+	return NewIndex([]Document{
+		{Type: "item", ID: 1, Fields: []Field{
+			{Text: "Colpo di Villano (Peasant's Strike)", Weight: 3},
+			{Text: "A rising cut from below", Weight: 1},
+		}},
+		{Type: "item", ID: 2, Fields: []Field{
+			{Text: "Posta di Finestra (Window Guard)", Weight: 3},
+			{Text: "A high guard beside the head", Weight: 1},
+			{Text: "Answer the colpo di villano from here", Weight: 1},
+		}},
+		{Type: "item", ID: 3, Fields: []Field{
+			{Text: "Zornhau", Weight: 3},
+			{Text: "Der Zornhau bricht alle Oberhäue", Weight: 1},
+		}},
+		{Type: "section", ID: 1, Fields: []Field{
+			{Text: "Guards", Weight: 3},
+			{Text: "Every guard of the longsword", Weight: 1},
+		}},
+	}, "resource", "section", "item")
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []Result
+	}{
+		{
+			name:     "title match ranks above body match",
+			query:    "colpo",
+			expected: []Result{{Type: "item", ID: 1}, {Type: "item", ID: 2}},
+		},
+		{
+			name:     "every term is required",
+			query:    "colpo finestra",
+			expected: []Result{{Type: "item", ID: 2}},
+		},
+		{
+			name:     "case and diacritics are folded",
+			query:    "OBERHAUE",
+			expected: []Result{{Type: "item", ID: 3}},
+		},
+		{
+			name:     "ties broken by type order",
+			query:    "guard",
+			expected: []Result{{Type: "item", ID: 2}, {Type: "section", ID: 1}},
+		},
+		{
+			name:  "unknown term",
+			query: "messer",
+		},
+		{
+			name:  "punctuation only",
+			query: "()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Result
+			for _, r := range ix.Search(tt.query) {
+				got = append(got, Result{Type: r.Type, ID: r.ID})
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIndex_Search_PhraseBonus(t *testing.T) {
+	results := testIndex().Search("colpo di villano")
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].ID != 1 || results[0].Score <= results[1].Score {
+		t.Errorf("expected item 1 to rank first by a clear margin, got %+v", results)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Posta di Finestra (Window-Guard), Übung")
+	expected := []string{"posta", "di", "finestra", "window", "guard", "ubung"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
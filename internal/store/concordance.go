@@ -0,0 +1,94 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"hema-lessons/internal/concordance"
+)
+
+// ConcordanceEntry is one item of a concordance, shown with its section,
+// resource and breadcrumb so versions from different treatises can be read
+// side by side. Relation is empty for the item the concordance was built for;
+// Direction is set for counter and prerequisite links only.
+type ConcordanceEntry struct {
+	ItemDetail
+	Relation  string `json:"relation,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// Concordance is every version of a technique, oldest treatise first, and
+// the techniques that counter it or build on it.
+type Concordance struct {
+	ItemID   int                `json:"item_id"`
+	Versions []ConcordanceEntry `json:"versions"`
+	Related  []ConcordanceEntry `json:"related"`
+}
+
+// GetConcordance assembles the Concordance of an item from any
+// ContentRepository, or returns nil if the item does not exist.
+func GetConcordance(repo ContentRepository, id int) (*Concordance, error) {
+	self, err := GetItemDetail(repo, id)
+	if err != nil || self == nil {
+		return nil, err
+	}
+
+	links, err := repo.ListConcordanceLinks()
+	if err != nil {
+		return nil, err
+	}
+	c := concordance.New(links)
+
+	result := &Concordance{
+		ItemID:   id,
+		Versions: []ConcordanceEntry{{ItemDetail: *self}},
+		Related:  []ConcordanceEntry{},
+	}
+
+	for _, v := range c.Versions(id) {
+		detail, err := concordanceDetail(repo, v.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		result.Versions = append(result.Versions, ConcordanceEntry{ItemDetail: *detail, Relation: v.Relation, Note: v.Note})
+	}
+	sort.SliceStable(result.Versions, func(i, j int) bool {
+		return olderResource(result.Versions[i].Resource, result.Versions[j].Resource)
+	})
+
+	for _, r := range c.Related(id) {
+		detail, err := concordanceDetail(repo, r.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		result.Related = append(result.Related, ConcordanceEntry{
+			ItemDetail: *detail, Relation: r.Type, Direction: r.Direction, Note: r.Note,
+		})
+	}
+
+	return result, nil
+}
+
+func concordanceDetail(repo ContentRepository, id int) (*ItemDetail, error) {
+	detail, err := GetItemDetail(repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if detail == nil {
+		return nil, fmt.Errorf("concordance: item %d not found", id)
+	}
+	return detail, nil
+}
+
+// olderResource orders resources by publication year, undated ones last,
+// then by ID.
+func olderResource(a, b ResourceWithAuthor) bool {
+	switch {
+	case a.PublicationYear != nil && b.PublicationYear != nil && *a.PublicationYear != *b.PublicationYear:
+		return *a.PublicationYear < *b.PublicationYear
+	case (a.PublicationYear == nil) != (b.PublicationYear == nil):
+		return a.PublicationYear != nil
+	}
+	return a.ID < b.ID
+}
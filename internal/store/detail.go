@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+
+	"hema-lessons/internal/models"
+)
+
+// Crumb is one step on the path from a resource down to a section, or a link
+// to a neighbouring section or item.
+type Crumb struct {
+	Type  string `json:"type"`
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+const (
+	CrumbResource = "resource"
+	CrumbSection  = "section"
+	CrumbItem     = "item"
+)
+
+// ItemDetail is an item together with everything needed to render it on its
+// own page: its section, its resource (with author name) and its breadcrumb.
+// Navigation is only set where the item is shown on its own page.
+type ItemDetail struct {
+	models.Item
+	Section    models.Section     `json:"section"`
+	Resource   ResourceWithAuthor `json:"resource"`
+	Breadcrumb []Crumb            `json:"breadcrumb"`
+	Navigation *Navigation        `json:"navigation,omitempty"`
+}
+
+// GetItemDetail assembles an ItemDetail from any ContentRepository, or returns
+// nil if the item does not exist.
+func GetItemDetail(repo ContentRepository, id int) (*ItemDetail, error) {
+	item, err := repo.GetItemByID(id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	section, err := repo.GetSectionByID(item.SectionID)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil {
+		return nil, fmt.Errorf("item %d: section %d not found", item.ID, item.SectionID)
+	}
+
+	resource, err := repo.GetResourceByID(section.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("section %d: resource %d not found", section.ID, section.ResourceID)
+	}
+
+	breadcrumb, err := SectionPath(repo, section.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ItemDetail{
+		Item:       *item,
+		Section:    *section,
+		Resource:   *resource,
+		Breadcrumb: breadcrumb,
+	}, nil
+}
+
+// SectionPath returns the breadcrumb from a section's resource down to the
+// section itself, following parent_id links. It returns nil if the section
+// does not exist.
+func SectionPath(repo ContentRepository, sectionID int) ([]Crumb, error) {
+	var sections []models.Section
+	seen := make(map[int]bool)
+
+	for id := sectionID; ; {
+		if seen[id] {
+			return nil, fmt.Errorf("section %d: parent_id cycle", sectionID)
+		}
+		seen[id] = true
+
+		sec, err := repo.GetSectionByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if sec == nil {
+			if len(sections) == 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("section %d: parent %d not found", sections[len(sections)-1].ID, id)
+		}
+		sections = append(sections, *sec)
+
+		if sec.ParentID == nil {
+			break
+		}
+		id = *sec.ParentID
+	}
+
+	root := sections[len(sections)-1]
+	resource, err := repo.GetResourceByID(root.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("section %d: resource %d not found", root.ID, root.ResourceID)
+	}
+
+	crumbs := make([]Crumb, 0, len(sections)+1)
+	crumbs = append(crumbs, Crumb{Type: CrumbResource, ID: resource.ID, Title: resource.Title})
+	for i := len(sections) - 1; i >= 0; i-- {
+		crumbs = append(crumbs, Crumb{Type: CrumbSection, ID: sections[i].ID, Title: sections[i].Title})
+	}
+
+	return crumbs, nil
+}
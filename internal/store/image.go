@@ -0,0 +1,201 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+
+	"hema-lessons/internal/assets"
+	"hema-lessons/internal/imaging"
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+)
+
+// Image URLs in the data files are paired with a models.Image carrying the
+// srcset of their resized variants and, when the store was given the assets
+// through WithImages, their size and placeholders. With WithAssetManifest the
+// URLs are also rewritten to their fingerprinted form. All of it is derived
+// when content is loaded so both backends serve the same thing.
+
+// Option configures how a Store or SQLiteStore loads content.
+type Option func(*options)
+
+type options struct {
+	images   *imaging.Catalog
+	manifest *assets.Manifest
+}
+
+// WithImages describes every image the content refers to through c when the
+// content is loaded, adding its width, height, aspect ratio, BlurHash and
+// dominant colour to the models. Images c cannot find are left without them;
+// hemalint reports such references.
+func WithImages(c *imaging.Catalog) Option {
+	return func(o *options) { o.images = c }
+}
+
+// WithAssetManifest rewrites the image URLs the content refers to, and their
+// srcsets, to the fingerprinted URLs of m. URLs m does not hold are kept.
+func WithAssetManifest(m *assets.Manifest) Option {
+	return func(o *options) { o.manifest = m }
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// imageIndex holds what is known about the images content refers to: their
+// metadata by logical URL, and the manifest their URLs are served through.
+// A nil *imageIndex knows nothing and rewrites nothing.
+type imageIndex struct {
+	metadata map[string]imaging.Metadata
+	manifest *assets.Manifest
+}
+
+// newImageIndex describes the images the dataset refers to.
+func newImageIndex(o options, d *dataset) *imageIndex {
+	ix := &imageIndex{manifest: o.manifest}
+	if o.images == nil {
+		return ix
+	}
+
+	var urls []string
+	for _, a := range d.authors {
+		if a.ImageURL != nil {
+			urls = append(urls, *a.ImageURL)
+		}
+	}
+	for _, r := range d.resources {
+		if r.CoverImageURL != nil {
+			urls = append(urls, *r.CoverImageURL)
+		}
+	}
+	for _, item := range d.items {
+		for _, url := range itemkind.ImageURLs(item.Kind, item.Attributes) {
+			urls = append(urls, url)
+		}
+	}
+
+	ix.metadata = make(map[string]imaging.Metadata, len(urls))
+	for _, url := range urls {
+		if _, done := ix.metadata[url]; done {
+			continue
+		}
+		m, err := o.images.Describe(url)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("failed to describe image", "url", url, "error", err)
+			}
+			continue
+		}
+		ix.metadata[url] = m
+	}
+	return ix
+}
+
+// url returns the URL an image at the logical url is served at.
+func (ix *imageIndex) url(url string) string {
+	if ix == nil {
+		return url
+	}
+	return ix.manifest.URL(url)
+}
+
+func (ix *imageIndex) image(url string) *models.Image {
+	var m imaging.Metadata
+	if ix != nil {
+		m = ix.metadata[url]
+	}
+	served := ix.url(url)
+	return &models.Image{
+		URL:           served,
+		Srcset:        imaging.Srcset(served, m.Width),
+		Width:         m.Width,
+		Height:        m.Height,
+		AspectRatio:   m.AspectRatio,
+		BlurHash:      m.BlurHash,
+		DominantColor: m.DominantColor,
+	}
+}
+
+// author sets the image of a, rewriting its image URL.
+func (ix *imageIndex) author(a *models.Author) {
+	if a.ImageURL == nil {
+		return
+	}
+	a.Image = ix.image(*a.ImageURL)
+	a.ImageURL = &a.Image.URL
+}
+
+// resource sets the cover image of r, rewriting its cover image URL.
+func (ix *imageIndex) resource(r *models.Resource) {
+	if r.CoverImageURL == nil {
+		return
+	}
+	r.CoverImage = ix.image(*r.CoverImageURL)
+	r.CoverImageURL = &r.CoverImage.URL
+}
+
+// item sets the images among an item's typed attributes, keyed by attribute
+// name, and rewrites their URLs in the attributes.
+func (ix *imageIndex) item(item *models.Item) {
+	urls := itemkind.ImageURLs(item.Kind, item.Attributes)
+	if len(urls) == 0 {
+		return
+	}
+	item.Images = make(map[string]models.Image, len(urls))
+	rewritten := make(map[string]string)
+	for field, url := range urls {
+		img := ix.image(url)
+		item.Images[field] = *img
+		if img.URL != url {
+			rewritten[field] = img.URL
+		}
+	}
+	if len(rewritten) > 0 {
+		item.Attributes = rewriteAttributes(item.Attributes, rewritten)
+	}
+}
+
+// rewriteAttributes returns raw with the given attributes set to new string
+// values. The attributes have already been decoded, so raw is an object.
+func rewriteAttributes(raw json.RawMessage, values map[string]string) json.RawMessage {
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &attrs); err != nil {
+		return raw
+	}
+	for field, value := range values {
+		attrs[field], _ = json.Marshal(value)
+	}
+	out, err := json.Marshal(attrs)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// withImages returns a copy of the dataset in which every author, resource
+// and item has the images derived from its image URLs.
+func (d *dataset) withImages(ix *imageIndex) *dataset {
+	c := *d
+	c.authors = make([]models.Author, len(d.authors))
+	for i, a := range d.authors {
+		ix.author(&a)
+		c.authors[i] = a
+	}
+	c.resources = make([]models.Resource, len(d.resources))
+	for i, r := range d.resources {
+		ix.resource(&r)
+		c.resources[i] = r
+	}
+	c.items = make([]models.Item, len(d.items))
+	for i, item := range d.items {
+		ix.item(&item)
+		c.items[i] = item
+	}
+	return &c
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/taxonomy"
+)
+
+// ImportFrom copies every author and item (with relations), resource, section,
+// concordance link and taxonomy term held by src into the database in a
+// single transaction. It refuses to run against a database that already holds
+// content, so it is safe to call on every startup.
+func (s *SQLiteStore) ImportFrom(src *Store) error {
+	empty, err := s.IsEmpty()
+	if err != nil {
+		return fmt.Errorf("checking database: %w", err)
+	}
+	if !empty {
+		return fmt.Errorf("database already contains content")
+	}
+	return s.importContent(src, false)
+}
+
+// SyncFrom replaces the content of the database with the content held by src
+// in a single transaction. It is how a database whose content predates a
+// migration (see ContentOutdated) gets the tables the migration added filled.
+func (s *SQLiteStore) SyncFrom(src *Store) error {
+	return s.importContent(src, true)
+}
+
+// contentTables lists the tables holding content, children before parents.
+var contentTables = []string{
+	"concordance_links", "item_relations", "items", "sections", "resources",
+	"author_relations", "authors", "taxonomy_terms",
+}
+
+func (s *SQLiteStore) importContent(src *Store, replace bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Sections may reference a parent with a higher ID, so foreign keys are
+	// checked at commit time rather than per insert.
+	if _, err := tx.Exec(`PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+	if replace {
+		for _, table := range contentTables {
+			if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+				return fmt.Errorf("clearing %s: %w", table, err)
+			}
+		}
+	}
+
+	// Copy the content as it was loaded, not as it is served: the database
+	// keeps logical image URLs and derives the served ones itself.
+	snap, err := newSnapshot(src.current().loaded, options{})
+	if err != nil {
+		return err
+	}
+	if err := importAuthors(tx, snap); err != nil {
+		return fmt.Errorf("importing authors: %w", err)
+	}
+	if err := importAuthorRelations(tx, snap); err != nil {
+		return fmt.Errorf("importing author relations: %w", err)
+	}
+	if err := importResources(tx, snap); err != nil {
+		return fmt.Errorf("importing resources: %w", err)
+	}
+	if err := importSections(tx, snap); err != nil {
+		return fmt.Errorf("importing sections: %w", err)
+	}
+	if err := importItems(tx, snap); err != nil {
+		return fmt.Errorf("importing items: %w", err)
+	}
+	if err := importItemRelations(tx, snap); err != nil {
+		return fmt.Errorf("importing item relations: %w", err)
+	}
+	if err := importConcordance(tx, snap); err != nil {
+		return fmt.Errorf("importing concordance: %w", err)
+	}
+	if err := importTaxonomy(tx, snap); err != nil {
+		return fmt.Errorf("importing taxonomy: %w", err)
+	}
+
+	// Record the schema the content was imported at, so ContentOutdated can
+	// tell when later migrations leave parts of it empty.
+	version, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO content_sync (id, schema_version) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET schema_version = excluded.schema_version`, version); err != nil {
+		return fmt.Errorf("recording content sync: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return s.rebuildIndexes()
+}
+
+func importAuthors(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO authors (id, name, bio, birth_year, death_year, image_url)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.authors) {
+		a := src.authors[id]
+		if _, err := stmt.Exec(a.ID, a.Name, a.Bio, a.BirthYear, a.DeathYear, a.ImageURL); err != nil {
+			return fmt.Errorf("author %d: %w", a.ID, err)
+		}
+	}
+	return nil
+}
+
+func importAuthorRelations(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO author_relations (author_id, related_author_id, type, note, position)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.authors) {
+		for i, rel := range src.authors[id].Relations {
+			if _, err := stmt.Exec(id, rel.AuthorID, rel.Type, rel.Note, i); err != nil {
+				return fmt.Errorf("author %d %s relation to %d: %w", id, rel.Type, rel.AuthorID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func importResources(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO resources (id, author_id, title, description, publication_year, cover_image_url, slug, former_slugs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.resources) {
+		r := src.resources[id]
+		formerSlugs, err := slugsColumn(r.FormerSlugs)
+		if err != nil {
+			return fmt.Errorf("resource %d: %w", r.ID, err)
+		}
+		if _, err := stmt.Exec(r.ID, r.AuthorID, r.Title, r.Description, r.PublicationYear, r.CoverImageURL, r.Slug, formerSlugs); err != nil {
+			return fmt.Errorf("resource %d: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+func importSections(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO sections (id, resource_id, parent_id, kind, title, description, position, tags, slug, former_slugs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.sections) {
+		sec := src.sections[id]
+		tags, err := tagsColumn(sec.Tags)
+		if err != nil {
+			return fmt.Errorf("section %d: %w", sec.ID, err)
+		}
+		formerSlugs, err := slugsColumn(sec.FormerSlugs)
+		if err != nil {
+			return fmt.Errorf("section %d: %w", sec.ID, err)
+		}
+		if _, err := stmt.Exec(sec.ID, sec.ResourceID, sec.ParentID, sec.Kind, sec.Title, sec.Description, sec.Position, tags,
+			sec.Slug, formerSlugs); err != nil {
+			return fmt.Errorf("section %d: %w", sec.ID, err)
+		}
+	}
+	return nil
+}
+
+func importItems(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO items (id, section_id, kind, title, description, position, attributes, tags, slug, former_slugs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.items) {
+		item := src.items[id]
+		if err := itemkind.Validate(item.Kind, item.Attributes); err != nil {
+			return fmt.Errorf("item %d: %w", item.ID, err)
+		}
+		var attributes interface{}
+		if len(item.Attributes) > 0 {
+			attributes = string(item.Attributes)
+		}
+		tags, err := tagsColumn(item.Tags)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", item.ID, err)
+		}
+		formerSlugs, err := slugsColumn(item.FormerSlugs)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", item.ID, err)
+		}
+		if _, err := stmt.Exec(item.ID, item.SectionID, item.Kind, item.Title, item.Description, item.Position, attributes, tags,
+			item.Slug, formerSlugs); err != nil {
+			return fmt.Errorf("item %d: %w", item.ID, err)
+		}
+	}
+	return nil
+}
+
+func importItemRelations(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO item_relations (item_id, related_item_id, type, note, position)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range sortedKeys(src.items) {
+		for i, rel := range src.items[id].Relations {
+			if _, err := stmt.Exec(id, rel.ItemID, rel.Type, rel.Note, i); err != nil {
+				return fmt.Errorf("item %d %s relation to %d: %w", id, rel.Type, rel.ItemID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func importConcordance(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO concordance_links (item_id, related_item_id, type, note, position)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, l := range src.concordance {
+		if _, err := stmt.Exec(l.ItemID, l.RelatedItemID, l.Type, l.Note, i); err != nil {
+			return fmt.Errorf("%s link from item %d to %d: %w", l.Type, l.ItemID, l.RelatedItemID, err)
+		}
+	}
+	return nil
+}
+
+func importTaxonomy(tx *sql.Tx, src *snapshot) error {
+	stmt, err := tx.Prepare(`INSERT INTO taxonomy_terms (facet, slug, name, position) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	tax := src.taxonomy.Taxonomy()
+	for _, facet := range taxonomy.Facets {
+		for i, term := range tax.Terms(facet) {
+			if _, err := stmt.Exec(facet, term.Slug, term.Name, i); err != nil {
+				return fmt.Errorf("%s term %q: %w", facet, term.Slug, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tagsColumn encodes tags for a nullable JSON column.
+func tagsColumn(tags *models.Tags) (interface{}, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// slugsColumn encodes former slugs for a nullable JSON column.
+func slugsColumn(slugs []string) (interface{}, error) {
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(slugs)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
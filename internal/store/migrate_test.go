@@ -0,0 +1,103 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestSyncFrom_FillsTablesAddedByMigrations opens a database left at each
+// earlier schema version with content in it, migrates it, and checks that the
+// re-sync fills the tables the later migrations added.
+func TestSyncFrom_FillsTablesAddedByMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := New()
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+
+	for n := 1; n < len(migrations); n++ {
+		t.Run(fmt.Sprintf("from version %d", migrations[n-1].version), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "content.db")
+			old, err := sql.Open("sqlite", "file:"+path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := applyMigrations(old, migrations[:n]); err != nil {
+				t.Fatalf("failed to migrate to %s: %v", migrations[n-1].name, err)
+			}
+			if _, err := old.Exec(`INSERT INTO authors (id, name) VALUES (1, 'Fiore dei Liberi');
+				INSERT INTO resources (id, author_id, title) VALUES (1, 1, 'Fior di Battaglia')`); err != nil {
+				t.Fatalf("failed to seed: %v", err)
+			}
+			old.Close()
+
+			db, err := OpenSQLite(path)
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			defer db.Close()
+
+			outdated, err := db.ContentOutdated()
+			if err != nil || !outdated {
+				t.Fatalf("expected content from before the migrations to be outdated, got %v, %v", outdated, err)
+			}
+			if err := db.SyncFrom(src); err != nil {
+				t.Fatalf("failed to re-sync: %v", err)
+			}
+			if outdated, err := db.ContentOutdated(); err != nil || outdated {
+				t.Errorf("expected re-synced content to be current, got %v, %v", outdated, err)
+			}
+
+			authors, err := db.ListAllAuthors()
+			if err != nil {
+				t.Fatal(err)
+			}
+			relations := 0
+			for _, a := range authors {
+				relations += len(a.Relations)
+			}
+			if relations == 0 {
+				t.Error("expected author relations")
+			}
+			if summary, _ := db.TagSummary(); summary == nil || len(summary.Weapons) == 0 {
+				t.Error("expected taxonomy terms")
+			}
+			if links, _ := db.ListConcordanceLinks(); len(links) == 0 {
+				t.Error("expected concordance links")
+			}
+			if graph, _ := db.ItemGraph(1, 1); graph == nil || len(graph.Edges) == 0 {
+				t.Errorf("expected item relations, got %+v", graph)
+			}
+			if r, _ := db.GetResourceByID(2); r == nil || r.Slug != "fior-di-battaglia" {
+				t.Errorf("expected the re-synced resources, got %+v", r)
+			}
+		})
+	}
+}
+
+func TestContentOutdated_FreshImport(t *testing.T) {
+	src, err := New()
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "content.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if outdated, err := db.ContentOutdated(); err != nil || outdated {
+		t.Errorf("expected an empty database not to be outdated, got %v, %v", outdated, err)
+	}
+	if err := db.ImportFrom(src); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if outdated, err := db.ContentOutdated(); err != nil || outdated {
+		t.Errorf("expected imported content to be current, got %v, %v", outdated, err)
+	}
+}
@@ -0,0 +1,44 @@
+CREATE TABLE authors (
+    id         INTEGER PRIMARY KEY,
+    name       TEXT    NOT NULL,
+    bio        TEXT    NOT NULL DEFAULT '',
+    birth_year INTEGER,
+    death_year INTEGER,
+    image_url  TEXT
+);
+
+CREATE TABLE resources (
+    id               INTEGER PRIMARY KEY,
+    author_id        INTEGER REFERENCES authors (id),
+    title            TEXT    NOT NULL,
+    description      TEXT    NOT NULL DEFAULT '',
+    publication_year INTEGER,
+    cover_image_url  TEXT
+);
+
+CREATE INDEX idx_resources_title ON resources (title);
+
+CREATE TABLE sections (
+    id          INTEGER PRIMARY KEY,
+    resource_id INTEGER NOT NULL REFERENCES resources (id),
+    parent_id   INTEGER REFERENCES sections (id),
+    kind        TEXT    NOT NULL,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    position    INTEGER NOT NULL
+);
+
+CREATE INDEX idx_sections_resource ON sections (resource_id, parent_id, position);
+CREATE INDEX idx_sections_parent ON sections (parent_id, position);
+
+CREATE TABLE items (
+    id          INTEGER PRIMARY KEY,
+    section_id  INTEGER NOT NULL REFERENCES sections (id),
+    kind        TEXT    NOT NULL,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    position    INTEGER NOT NULL,
+    attributes  TEXT CHECK (attributes IS NULL OR json_valid(attributes))
+);
+
+CREATE INDEX idx_items_section ON items (section_id, position);
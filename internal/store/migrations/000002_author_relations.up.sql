@@ -0,0 +1,10 @@
+CREATE TABLE author_relations (
+    author_id         INTEGER NOT NULL REFERENCES authors (id),
+    related_author_id INTEGER NOT NULL REFERENCES authors (id),
+    type              TEXT    NOT NULL CHECK (type IN ('teacher_of', 'glossed_by', 'tradition')),
+    note              TEXT    NOT NULL DEFAULT '',
+    position          INTEGER NOT NULL,
+    PRIMARY KEY (author_id, type, related_author_id)
+);
+
+CREATE INDEX idx_author_relations_related ON author_relations (related_author_id);
@@ -0,0 +1,10 @@
+CREATE TABLE taxonomy_terms (
+    facet    TEXT    NOT NULL CHECK (facet IN ('weapon', 'guard', 'action', 'armour')),
+    slug     TEXT    NOT NULL,
+    name     TEXT    NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (facet, slug)
+);
+
+ALTER TABLE sections ADD COLUMN tags TEXT CHECK (tags IS NULL OR json_valid(tags));
+ALTER TABLE items ADD COLUMN tags TEXT CHECK (tags IS NULL OR json_valid(tags));
@@ -0,0 +1,10 @@
+CREATE TABLE concordance_links (
+    item_id         INTEGER NOT NULL REFERENCES items (id),
+    related_item_id INTEGER NOT NULL REFERENCES items (id),
+    type            TEXT    NOT NULL CHECK (type IN ('same', 'variant', 'counter', 'prerequisite')),
+    note            TEXT    NOT NULL DEFAULT '',
+    position        INTEGER NOT NULL PRIMARY KEY
+);
+
+CREATE INDEX idx_concordance_links_item ON concordance_links (item_id);
+CREATE INDEX idx_concordance_links_related ON concordance_links (related_item_id);
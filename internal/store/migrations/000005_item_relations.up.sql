@@ -0,0 +1,10 @@
+CREATE TABLE item_relations (
+    item_id         INTEGER NOT NULL REFERENCES items (id),
+    related_item_id INTEGER NOT NULL REFERENCES items (id),
+    type            TEXT    NOT NULL CHECK (type IN ('counters', 'is_countered_by', 'follow_up', 'is_remedy_of')),
+    note            TEXT    NOT NULL DEFAULT '',
+    position        INTEGER NOT NULL,
+    PRIMARY KEY (item_id, type, related_item_id)
+);
+
+CREATE INDEX idx_item_relations_related ON item_relations (related_item_id);
@@ -0,0 +1,6 @@
+ALTER TABLE resources ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE resources ADD COLUMN former_slugs TEXT CHECK (former_slugs IS NULL OR json_valid(former_slugs));
+ALTER TABLE sections ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE sections ADD COLUMN former_slugs TEXT CHECK (former_slugs IS NULL OR json_valid(former_slugs));
+ALTER TABLE items ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN former_slugs TEXT CHECK (former_slugs IS NULL OR json_valid(former_slugs));
@@ -0,0 +1,4 @@
+CREATE TABLE content_sync (
+    id             INTEGER PRIMARY KEY CHECK (id = 1),
+    schema_version INTEGER NOT NULL
+);
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"fmt"
+
+	"hema-lessons/internal/models"
+)
+
+// Navigation links a section or item to its neighbours. Previous and Next are
+// the siblings just before and after it: sections with the same parent (or
+// root sections of the same resource), or items of the same section. NextItem
+// is the next item in reading order, which carries on into the following
+// sections of the resource when the current section runs out.
+type Navigation struct {
+	Previous *Crumb `json:"previous,omitempty"`
+	Next     *Crumb `json:"next,omitempty"`
+	NextItem *Crumb `json:"next_item,omitempty"`
+}
+
+// SectionDetail is a section with its breadcrumb and navigation links.
+type SectionDetail struct {
+	models.Section
+	Breadcrumb []Crumb    `json:"breadcrumb"`
+	Navigation Navigation `json:"navigation"`
+}
+
+// GetSectionDetail assembles a SectionDetail from any ContentRepository, or
+// returns nil if the section does not exist. The breadcrumb runs from the
+// resource down to the section's parent.
+func GetSectionDetail(repo ContentRepository, id int) (*SectionDetail, error) {
+	section, err := repo.GetSectionByID(id)
+	if err != nil || section == nil {
+		return nil, err
+	}
+
+	path, err := SectionPath(repo, section.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := resourceReadingOrder(repo, section.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var nav Navigation
+	siblings := order.siblings[section.ID]
+	for i, sib := range siblings {
+		if sib.ID != section.ID {
+			continue
+		}
+		if i > 0 {
+			nav.Previous = sectionCrumb(siblings[i-1])
+		}
+		if i < len(siblings)-1 {
+			nav.Next = sectionCrumb(siblings[i+1])
+		}
+	}
+	if next, ok := order.firstItem[section.ID]; ok {
+		nav.NextItem = itemCrumb(order.items[next])
+	}
+
+	return &SectionDetail{
+		Section:    *section,
+		Breadcrumb: path[:len(path)-1],
+		Navigation: nav,
+	}, nil
+}
+
+// ItemNavigation returns the navigation links of an item within its resource.
+func ItemNavigation(repo ContentRepository, item *models.Item) (*Navigation, error) {
+	section, err := repo.GetSectionByID(item.SectionID)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil {
+		return nil, fmt.Errorf("item %d: section %d not found", item.ID, item.SectionID)
+	}
+
+	order, err := resourceReadingOrder(repo, section.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	nav := &Navigation{}
+	i, ok := order.position[item.ID]
+	if !ok {
+		return nav, nil
+	}
+	if i > 0 && order.items[i-1].SectionID == item.SectionID {
+		nav.Previous = itemCrumb(order.items[i-1])
+	}
+	if i < len(order.items)-1 {
+		next := order.items[i+1]
+		if next.SectionID == item.SectionID {
+			nav.Next = itemCrumb(next)
+		}
+		nav.NextItem = itemCrumb(next)
+	}
+	return nav, nil
+}
+
+// readingOrder is a resource's content in reading order: each section's items,
+// then its child sections, then its next sibling.
+type readingOrder struct {
+	items     []models.Item
+	position  map[int]int              // item ID -> index in items
+	firstItem map[int]int              // section ID -> index of the first item from the section's start
+	siblings  map[int][]models.Section // section ID -> the section and its siblings, by position
+}
+
+// readingOrderIndex is implemented by the stores, which build the reading
+// order of every resource along with its tree instead of on each request.
+type readingOrderIndex interface {
+	readingOrder(resourceID int) *readingOrder
+}
+
+// resourceReadingOrder returns the reading order of a resource, from the
+// repository's precomputed orders where it keeps them.
+func resourceReadingOrder(repo ContentRepository, resourceID int) (*readingOrder, error) {
+	if idx, ok := repo.(readingOrderIndex); ok {
+		if order := idx.readingOrder(resourceID); order != nil {
+			return order, nil
+		}
+		return nil, fmt.Errorf("resource %d not found", resourceID)
+	}
+	tree, err := repo.ResourceTree(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("resource %d not found", resourceID)
+	}
+	return newReadingOrder(tree), nil
+}
+
+// buildReadingOrders builds the reading order of every tree, keyed by
+// resource ID.
+func buildReadingOrders(trees map[int]*ResourceTree) map[int]*readingOrder {
+	orders := make(map[int]*readingOrder, len(trees))
+	for id, tree := range trees {
+		orders[id] = newReadingOrder(tree)
+	}
+	return orders
+}
+
+func newReadingOrder(tree *ResourceTree) *readingOrder {
+	order := &readingOrder{
+		position:  make(map[int]int),
+		firstItem: make(map[int]int),
+		siblings:  make(map[int][]models.Section),
+	}
+	var starts []int // sections whose first item has not been reached yet
+	var walk func(nodes []TreeNode)
+	walk = func(nodes []TreeNode) {
+		siblings := make([]models.Section, len(nodes))
+		for i, n := range nodes {
+			siblings[i] = n.Section
+		}
+		for _, n := range nodes {
+			order.siblings[n.ID] = siblings
+			starts = append(starts, n.ID)
+			for _, item := range n.Items {
+				for _, id := range starts {
+					order.firstItem[id] = len(order.items)
+				}
+				starts = starts[:0]
+				order.position[item.ID] = len(order.items)
+				order.items = append(order.items, item)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(tree.Sections)
+	return order
+}
+
+func sectionCrumb(sec models.Section) *Crumb {
+	return &Crumb{Type: CrumbSection, ID: sec.ID, Title: sec.Title}
+}
+
+func itemCrumb(item models.Item) *Crumb {
+	return &Crumb{Type: CrumbItem, ID: item.ID, Title: item.Title}
+}
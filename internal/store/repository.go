@@ -0,0 +1,56 @@
+package store
+
+import (
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/search"
+	"hema-lessons/internal/suggest"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/techgraph"
+)
+
+// ContentRepository is the read-side interface handlers use to access content.
+// Lookups that find nothing return a nil pointer or an empty slice; a non-nil
+// error means the backend itself failed. Slug lookups also match former slugs.
+//
+// Content may be reloaded between two calls. Callers that make several calls
+// to answer one request make them through View, which always reads the
+// content as it was when the view was taken.
+type ContentRepository interface {
+	View() ContentRepository
+
+	ListAuthors(params pagination.Params) ([]models.Author, int, error)
+	GetAuthorByID(id int) (*models.Author, error)
+	ListAllAuthors() ([]models.Author, error)
+
+	ListResources(params pagination.Params) ([]ResourceWithAuthor, int, error)
+	GetResourceByID(id int) (*ResourceWithAuthor, error)
+	GetResourceBySlug(slug string) (*ResourceWithAuthor, error)
+	ResourceExists(id int) (bool, error)
+	ListResourcesByAuthorID(authorID int) ([]ResourceWithAuthor, error)
+	ResourceTree(resourceID int) (*ResourceTree, error)
+
+	ListRootSectionsByResourceID(resourceID int) ([]models.Section, error)
+	GetSectionByID(id int) (*models.Section, error)
+	GetSectionBySlug(resourceID int, slug string) (*models.Section, error)
+	ListChildSections(parentID int) ([]models.Section, error)
+
+	ListItemsBySectionID(sectionID int) ([]models.Item, error)
+	GetItemByID(id int) (*models.Item, error)
+	GetItemBySlug(resourceID int, slug string) (*models.Item, error)
+
+	ItemGraph(id, depth int) (*techgraph.Graph, error)
+	SectionGraph(sectionID int) (*techgraph.Graph, error)
+
+	ListConcordanceLinks() ([]models.ConcordanceLink, error)
+
+	Search(query string) ([]search.Result, error)
+	Suggest(prefix string, limit int) ([]suggest.Entry, error)
+
+	TagSummary() (*taxonomy.Summary, error)
+	ListItemsByTags(filter taxonomy.Filter, params pagination.Params) ([]TaggedItem, int, error)
+
+	Version() (Version, error)
+}
+
+var _ ContentRepository = (*Store)(nil)
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"fmt"
+
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/search"
+	"hema-lessons/internal/suggest"
+)
+
+// Search hit types.
+const (
+	HitResource = "resource"
+	HitSection  = "section"
+	HitItem     = "item"
+)
+
+// Field weights used when indexing content for search.
+const (
+	titleWeight       = 3
+	descriptionWeight = 1
+	attributeWeight   = 1
+)
+
+// SearchHit is a single search result, with the breadcrumb leading to it.
+// The breadcrumb of a resource is empty; that of a section or item runs from
+// its resource down to its parent section.
+type SearchHit struct {
+	Type        string  `json:"type"`
+	ID          int     `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Score       float64 `json:"score"`
+	Breadcrumb  []Crumb `json:"breadcrumb"`
+}
+
+// newSearchIndex indexes the titles and descriptions of resources, sections
+// and items, plus the searchable attributes of items (such as instructions).
+func newSearchIndex(resources []models.Resource, sections []models.Section, items []models.Item) *search.Index {
+	docs := make([]search.Document, 0, len(resources)+len(sections)+len(items))
+	for _, r := range resources {
+		docs = append(docs, search.Document{Type: HitResource, ID: r.ID, Fields: []search.Field{
+			{Text: r.Title, Weight: titleWeight},
+			{Text: r.Description, Weight: descriptionWeight},
+		}})
+	}
+	for _, sec := range sections {
+		docs = append(docs, search.Document{Type: HitSection, ID: sec.ID, Fields: []search.Field{
+			{Text: sec.Title, Weight: titleWeight},
+			{Text: sec.Description, Weight: descriptionWeight},
+		}})
+	}
+	for _, item := range items {
+		docs = append(docs, search.Document{Type: HitItem, ID: item.ID, Fields: []search.Field{
+			{Text: item.Title, Weight: titleWeight},
+			{Text: item.Description, Weight: descriptionWeight},
+		}})
+		for _, text := range itemSearchText(item) {
+			docs[len(docs)-1].Fields = append(docs[len(docs)-1].Fields, search.Field{Text: text, Weight: attributeWeight})
+		}
+	}
+	return search.NewIndex(docs, HitResource, HitSection, HitItem)
+}
+
+// itemSearchText returns the free text of an item's typed attributes.
+// Attributes that do not match their kind's schema contribute nothing.
+func itemSearchText(item models.Item) []string {
+	attrs, err := itemkind.Decode(item.Kind, item.Attributes)
+	if err != nil {
+		return nil
+	}
+	if s, ok := attrs.(itemkind.Searchable); ok {
+		return s.SearchText()
+	}
+	return nil
+}
+
+// newSuggestTrie indexes section and item titles for type-ahead suggestions.
+func newSuggestTrie(sections []models.Section, items []models.Item) *suggest.Trie {
+	entries := make([]suggest.Entry, 0, len(sections)+len(items))
+	for _, sec := range sections {
+		entries = append(entries, suggest.Entry{Type: HitSection, ID: sec.ID, Title: sec.Title})
+	}
+	for _, item := range items {
+		entries = append(entries, suggest.Entry{Type: HitItem, ID: item.ID, Title: item.Title})
+	}
+	return suggest.New(entries, HitSection, HitItem)
+}
+
+// SearchContent runs query against repo and returns one page of hits with
+// their titles and breadcrumbs, plus the total number of hits.
+func SearchContent(repo ContentRepository, query string, params pagination.Params) ([]SearchHit, int, error) {
+	results, err := repo.Search(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := paginate(results, params)
+	if len(page) == 0 {
+		return nil, len(results), nil
+	}
+
+	hits := make([]SearchHit, 0, len(page))
+	for _, res := range page {
+		hit, err := resolveHit(repo, res)
+		if err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, *hit)
+	}
+	return hits, len(results), nil
+}
+
+func resolveHit(repo ContentRepository, res search.Result) (*SearchHit, error) {
+	hit := &SearchHit{Type: res.Type, ID: res.ID, Score: res.Score, Breadcrumb: []Crumb{}}
+
+	switch res.Type {
+	case HitResource:
+		r, err := repo.GetResourceByID(res.ID)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, fmt.Errorf("search hit: resource %d not found", res.ID)
+		}
+		hit.Title, hit.Description = r.Title, r.Description
+
+	case HitSection:
+		sec, err := repo.GetSectionByID(res.ID)
+		if err != nil {
+			return nil, err
+		}
+		if sec == nil {
+			return nil, fmt.Errorf("search hit: section %d not found", res.ID)
+		}
+		hit.Title, hit.Description = sec.Title, sec.Description
+
+		path, err := SectionPath(repo, sec.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > 0 {
+			hit.Breadcrumb = path[:len(path)-1]
+		}
+
+	case HitItem:
+		item, err := repo.GetItemByID(res.ID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, fmt.Errorf("search hit: item %d not found", res.ID)
+		}
+		hit.Title, hit.Description = item.Title, item.Description
+
+		path, err := SectionPath(repo, item.SectionID)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			hit.Breadcrumb = path
+		}
+
+	default:
+		return nil, fmt.Errorf("search hit: unknown type %q", res.Type)
+	}
+
+	return hit, nil
+}
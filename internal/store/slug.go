@@ -0,0 +1,95 @@
+package store
+
+import (
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/textnorm"
+)
+
+// Slugs name resources, sections and items in permalinks. A record without
+// an explicit slug in the data files takes the slug of its title, so
+// "Fior di Battaglia" is fior-di-battaglia. Resource slugs are unique across
+// resources; section and item slugs are unique within their resource. Former
+// slugs keep old permalinks working after a record is retitled.
+
+func resourceSlug(r models.Resource) string {
+	if r.Slug != "" {
+		return r.Slug
+	}
+	return textnorm.Slugify(r.Title)
+}
+
+func sectionSlug(sec models.Section) string {
+	if sec.Slug != "" {
+		return sec.Slug
+	}
+	return textnorm.Slugify(sec.Title)
+}
+
+func itemSlug(item models.Item) string {
+	if item.Slug != "" {
+		return item.Slug
+	}
+	return textnorm.Slugify(item.Title)
+}
+
+// slugIndex maps current and former slugs to IDs.
+type slugIndex struct {
+	resources map[string]int
+	sections  map[int]map[string]int // resource ID -> slug -> section ID
+	items     map[int]map[string]int // resource ID -> slug -> item ID
+}
+
+// newSlugIndex indexes slugs, which must already be set on every record.
+func newSlugIndex(resources []models.Resource, sections []models.Section, items []models.Item) *slugIndex {
+	ix := &slugIndex{
+		resources: make(map[string]int, len(resources)),
+		sections:  make(map[int]map[string]int),
+		items:     make(map[int]map[string]int),
+	}
+	for _, r := range resources {
+		addSlugs(ix.resources, r.ID, r.Slug, r.FormerSlugs)
+	}
+
+	resourceBySection := make(map[int]int, len(sections))
+	for _, sec := range sections {
+		resourceBySection[sec.ID] = sec.ResourceID
+		if ix.sections[sec.ResourceID] == nil {
+			ix.sections[sec.ResourceID] = make(map[string]int)
+		}
+		addSlugs(ix.sections[sec.ResourceID], sec.ID, sec.Slug, sec.FormerSlugs)
+	}
+	for _, item := range items {
+		resourceID := resourceBySection[item.SectionID]
+		if ix.items[resourceID] == nil {
+			ix.items[resourceID] = make(map[string]int)
+		}
+		addSlugs(ix.items[resourceID], item.ID, item.Slug, item.FormerSlugs)
+	}
+	return ix
+}
+
+// addSlugs adds a record's slugs to m. Current slugs win over former ones, so
+// a clash in unvalidated data still resolves every current slug.
+func addSlugs(m map[string]int, id int, slug string, former []string) {
+	for _, f := range former {
+		if _, taken := m[f]; !taken {
+			m[f] = id
+		}
+	}
+	m[slug] = id
+}
+
+// resource returns the ID of the resource with slug, or 0.
+func (ix *slugIndex) resource(slug string) int {
+	return ix.resources[slug]
+}
+
+// section returns the ID of the section of a resource with slug, or 0.
+func (ix *slugIndex) section(resourceID int, slug string) int {
+	return ix.sections[resourceID][slug]
+}
+
+// item returns the ID of the item of a resource with slug, or 0.
+func (ix *slugIndex) item(resourceID int, slug string) int {
+	return ix.items[resourceID][slug]
+}
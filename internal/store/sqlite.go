@@ -0,0 +1,854 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	_ "modernc.org/sqlite"
+
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/search"
+	"hema-lessons/internal/suggest"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/techgraph"
+)
+
+// SQLiteStore is a ContentRepository backed by an on-disk SQLite database.
+// Search, Suggest, tag filtering, technique graphs, resource trees and slug
+// lookups run against in-memory indexes built from the database when it is
+// opened and rebuilt after ImportFrom. Rows written before slugs were stored
+// take the slug of their title.
+//
+// The indexes are swapped together as one generation, so a View keeps
+// answering from the generation it was taken from. Rows only change through
+// ImportFrom, which builds a new generation.
+type SQLiteStore struct {
+	db   *sql.DB
+	opts options
+	gen  atomic.Pointer[sqliteIndexes]
+}
+
+// sqliteIndexes is one generation of the in-memory indexes.
+type sqliteIndexes struct {
+	images  *imageIndex
+	trees   map[int]*ResourceTree
+	orders  map[int]*readingOrder
+	slugs   *slugIndex
+	search  *search.Index
+	suggest *suggest.Trie
+	tags    *taxonomy.Index
+	graph   *techgraph.Index
+	version Version
+}
+
+var _ ContentRepository = (*SQLiteStore)(nil)
+
+// OpenSQLite opens (creating if needed) the SQLite database at path and applies
+// any pending schema migrations.
+func OpenSQLite(path string, opts ...Option) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", path, err)
+	}
+
+	s := &SQLiteStore{db: db, opts: newOptions(opts)}
+	if err := s.rebuildIndexes(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("indexing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// View returns a repository pinned to the current generation of indexes. It
+// shares the database with s and must not be closed.
+func (s *SQLiteStore) View() ContentRepository {
+	return s.withIndexes(s.indexes())
+}
+
+func (s *SQLiteStore) withIndexes(ix *sqliteIndexes) *SQLiteStore {
+	v := &SQLiteStore{db: s.db, opts: s.opts}
+	v.gen.Store(ix)
+	return v
+}
+
+// Close closes the underlying database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// ContentOutdated reports whether the database holds content imported before
+// the latest migrations, whose new tables and columns it therefore leaves
+// empty. Such content should be replaced with SyncFrom.
+func (s *SQLiteStore) ContentOutdated() (bool, error) {
+	empty, err := s.IsEmpty()
+	if err != nil || empty {
+		return false, err
+	}
+	version, err := schemaVersion(s.db)
+	if err != nil {
+		return false, err
+	}
+	var synced int
+	err = s.db.QueryRow(`SELECT schema_version FROM content_sync WHERE id = 1`).Scan(&synced)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return synced < version, nil
+}
+
+// IsEmpty returns true if the database holds no resources.
+func (s *SQLiteStore) IsEmpty() (bool, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM resources`).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// --- Authors ---
+
+const authorColumns = `id, name, bio, birth_year, death_year, image_url`
+
+// ListAuthors returns a paginated list of authors, ordered by name.
+func (s *SQLiteStore) ListAuthors(params pagination.Params) ([]models.Author, int, error) {
+	var totalCount int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM authors`).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+	if totalCount == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := s.db.Query(`SELECT `+authorColumns+` FROM authors
+		ORDER BY name, id
+		LIMIT ? OFFSET ?`, params.PageSize, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var authors []models.Author
+	for rows.Next() {
+		a, err := scanAuthor(rows, s.imageIndex())
+		if err != nil {
+			return nil, 0, err
+		}
+		authors = append(authors, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachRelations(authors); err != nil {
+		return nil, 0, err
+	}
+
+	return authors, totalCount, nil
+}
+
+// GetAuthorByID returns a single author, or nil if not found.
+func (s *SQLiteStore) GetAuthorByID(id int) (*models.Author, error) {
+	row := s.db.QueryRow(`SELECT `+authorColumns+` FROM authors WHERE id = ?`, id)
+
+	a, err := scanAuthor(row, s.imageIndex())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	authors := []models.Author{*a}
+	if err := s.attachRelations(authors); err != nil {
+		return nil, err
+	}
+	return &authors[0], nil
+}
+
+// ListAllAuthors returns every author with their relations, ordered by name.
+func (s *SQLiteStore) ListAllAuthors() ([]models.Author, error) {
+	return s.listAllAuthors(s.imageIndex())
+}
+
+func (s *SQLiteStore) listAllAuthors(images *imageIndex) ([]models.Author, error) {
+	rows, err := s.db.Query(`SELECT ` + authorColumns + ` FROM authors ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := []models.Author{}
+	for rows.Next() {
+		a, err := scanAuthor(rows, images)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.attachRelations(authors); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// attachRelations fills in the Relations of each author, in their original order.
+func (s *SQLiteStore) attachRelations(authors []models.Author) error {
+	if len(authors) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(authors))
+	placeholders := make([]string, len(authors))
+	args := make([]interface{}, len(authors))
+	for i, a := range authors {
+		index[a.ID] = i
+		placeholders[i] = "?"
+		args[i] = a.ID
+	}
+
+	rows, err := s.db.Query(`SELECT author_id, type, related_author_id, note FROM author_relations
+		WHERE author_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY author_id, position`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			authorID int
+			rel      models.AuthorRelation
+		)
+		if err := rows.Scan(&authorID, &rel.Type, &rel.AuthorID, &rel.Note); err != nil {
+			return err
+		}
+		i := index[authorID]
+		authors[i].Relations = append(authors[i].Relations, rel)
+	}
+	return rows.Err()
+}
+
+// --- Resources ---
+
+const resourceWithAuthorColumns = `r.id, r.author_id, r.title, r.description, r.publication_year, r.cover_image_url, COALESCE(a.name, ''), r.slug, r.former_slugs`
+
+// ListResources returns a paginated list of resources with their author names, ordered by title.
+func (s *SQLiteStore) ListResources(params pagination.Params) ([]ResourceWithAuthor, int, error) {
+	var totalCount int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM resources`).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+	if totalCount == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := s.db.Query(`SELECT `+resourceWithAuthorColumns+`
+		FROM resources r LEFT JOIN authors a ON a.id = r.author_id
+		ORDER BY r.title, r.id
+		LIMIT ? OFFSET ?`, params.PageSize, params.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var resources []ResourceWithAuthor
+	for rows.Next() {
+		rwa, err := scanResourceWithAuthor(rows, s.imageIndex())
+		if err != nil {
+			return nil, 0, err
+		}
+		resources = append(resources, *rwa)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return resources, totalCount, nil
+}
+
+// GetResourceByID returns a single resource with its author name, or nil if not found.
+func (s *SQLiteStore) GetResourceByID(id int) (*ResourceWithAuthor, error) {
+	row := s.db.QueryRow(`SELECT `+resourceWithAuthorColumns+`
+		FROM resources r LEFT JOIN authors a ON a.id = r.author_id
+		WHERE r.id = ?`, id)
+
+	rwa, err := scanResourceWithAuthor(row, s.imageIndex())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rwa, nil
+}
+
+// GetResourceBySlug returns the resource with a current or former slug, or
+// nil if there is none.
+func (s *SQLiteStore) GetResourceBySlug(slug string) (*ResourceWithAuthor, error) {
+	return s.GetResourceByID(s.indexes().slugs.resource(slug))
+}
+
+// ResourceExists returns true if a resource with the given ID exists.
+func (s *SQLiteStore) ResourceExists(id int) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM resources WHERE id = ?)`, id).Scan(&exists)
+	return exists, err
+}
+
+// ListResourcesByAuthorID returns the resources written by an author, ordered by
+// publication year, title and ID. Resources without a year sort last. An
+// author without resources has an empty, non-nil list.
+func (s *SQLiteStore) ListResourcesByAuthorID(authorID int) ([]ResourceWithAuthor, error) {
+	rows, err := s.db.Query(`SELECT `+resourceWithAuthorColumns+`
+		FROM resources r JOIN authors a ON a.id = r.author_id
+		WHERE r.author_id = ?
+		ORDER BY r.publication_year IS NULL, r.publication_year, r.title, r.id`, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := []ResourceWithAuthor{}
+	for rows.Next() {
+		rwa, err := scanResourceWithAuthor(rows, s.imageIndex())
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *rwa)
+	}
+	return resources, rows.Err()
+}
+
+// ResourceTree returns the table of contents of a resource as built by the
+// last rebuildIndexes, or nil if the resource does not exist.
+func (s *SQLiteStore) ResourceTree(resourceID int) (*ResourceTree, error) {
+	return s.indexes().trees[resourceID], nil
+}
+
+// readingOrder returns the precomputed reading order of a resource, or nil
+// if it does not exist.
+func (s *SQLiteStore) readingOrder(resourceID int) *readingOrder {
+	return s.indexes().orders[resourceID]
+}
+
+// --- Sections ---
+
+const sectionColumns = `id, resource_id, parent_id, kind, title, description, position, tags, slug, former_slugs`
+
+// ListRootSectionsByResourceID returns top-level sections for a given resource, ordered by position.
+func (s *SQLiteStore) ListRootSectionsByResourceID(resourceID int) ([]models.Section, error) {
+	return s.querySections(`SELECT `+sectionColumns+` FROM sections
+		WHERE resource_id = ? AND parent_id IS NULL
+		ORDER BY position, id`, resourceID)
+}
+
+// GetSectionByID returns a single section, or nil if not found.
+func (s *SQLiteStore) GetSectionByID(id int) (*models.Section, error) {
+	row := s.db.QueryRow(`SELECT `+sectionColumns+` FROM sections WHERE id = ?`, id)
+
+	sec, err := scanSection(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+// GetSectionBySlug returns the section of a resource with a current or
+// former slug, or nil if there is none.
+func (s *SQLiteStore) GetSectionBySlug(resourceID int, slug string) (*models.Section, error) {
+	return s.GetSectionByID(s.indexes().slugs.section(resourceID, slug))
+}
+
+// ListChildSections returns direct child sections of a given parent section, ordered by position.
+func (s *SQLiteStore) ListChildSections(parentID int) ([]models.Section, error) {
+	return s.querySections(`SELECT `+sectionColumns+` FROM sections
+		WHERE parent_id = ?
+		ORDER BY position, id`, parentID)
+}
+
+func (s *SQLiteStore) querySections(query string, args ...interface{}) ([]models.Section, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []models.Section
+	for rows.Next() {
+		sec, err := scanSection(rows)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, *sec)
+	}
+	return sections, rows.Err()
+}
+
+// --- Items ---
+
+const itemColumns = `id, section_id, kind, title, description, position, attributes, tags, slug, former_slugs`
+
+// ListItemsBySectionID returns items for a given section, ordered by position.
+func (s *SQLiteStore) ListItemsBySectionID(sectionID int) ([]models.Item, error) {
+	return s.queryItems(s.imageIndex(), `SELECT `+itemColumns+` FROM items
+		WHERE section_id = ?
+		ORDER BY position, id`, sectionID)
+}
+
+// GetItemByID returns a single item, or nil if not found.
+func (s *SQLiteStore) GetItemByID(id int) (*models.Item, error) {
+	row := s.db.QueryRow(`SELECT `+itemColumns+` FROM items WHERE id = ?`, id)
+
+	item, err := scanItem(row, s.imageIndex())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := []models.Item{*item}
+	if err := s.attachItemRelations(items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// GetItemBySlug returns the item of a resource with a current or former slug,
+// or nil if there is none.
+func (s *SQLiteStore) GetItemBySlug(resourceID int, slug string) (*models.Item, error) {
+	return s.GetItemByID(s.indexes().slugs.item(resourceID, slug))
+}
+
+// queryItems runs an item query, deriving images through the given index.
+func (s *SQLiteStore) queryItems(images *imageIndex, query string, args ...interface{}) ([]models.Item, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.Item
+	for rows.Next() {
+		item, err := scanItem(rows, images)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.attachItemRelations(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// attachItemRelations fills in the Relations of each item, in their original order.
+func (s *SQLiteStore) attachItemRelations(items []models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(items))
+	placeholders := make([]string, len(items))
+	args := make([]interface{}, len(items))
+	for i, item := range items {
+		index[item.ID] = i
+		placeholders[i] = "?"
+		args[i] = item.ID
+	}
+
+	rows, err := s.db.Query(`SELECT item_id, type, related_item_id, note FROM item_relations
+		WHERE item_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY item_id, position`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			itemID int
+			rel    models.ItemRelation
+		)
+		if err := rows.Scan(&itemID, &rel.Type, &rel.ItemID, &rel.Note); err != nil {
+			return err
+		}
+		i := index[itemID]
+		items[i].Relations = append(items[i].Relations, rel)
+	}
+	return rows.Err()
+}
+
+// --- Search ---
+
+// Search returns every resource, section and item matching query, best first.
+func (s *SQLiteStore) Search(query string) ([]search.Result, error) {
+	return s.indexes().search.Search(query), nil
+}
+
+// Suggest returns up to limit section and item titles starting with prefix.
+func (s *SQLiteStore) Suggest(prefix string, limit int) ([]suggest.Entry, error) {
+	return s.indexes().suggest.Suggest(prefix, limit), nil
+}
+
+// rebuildIndexes reads the taxonomy and every resource, section and item and
+// swaps in fresh search, suggestion, taxonomy, technique graph and slug
+// indexes and resource trees, and a content version hashed over all tables.
+func (s *SQLiteStore) rebuildIndexes() error {
+	rows, err := s.db.Query(`SELECT ` + resourceWithAuthorColumns + `
+		FROM resources r LEFT JOIN authors a ON a.id = r.author_id
+		ORDER BY r.id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Rows are read as stored, with their logical image URLs; images are
+	// derived below once the new image index is built.
+	var resources []models.Resource
+	for rows.Next() {
+		rwa, err := scanResourceWithAuthor(rows, nil)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, rwa.Resource)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sections, err := s.querySections(`SELECT ` + sectionColumns + ` FROM sections ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	items, err := s.queryItems(nil, `SELECT `+itemColumns+` FROM items ORDER BY id`)
+	if err != nil {
+		return err
+	}
+
+	tax, err := s.loadTaxonomy()
+	if err != nil {
+		return err
+	}
+	authors, err := s.listAllAuthors(nil)
+	if err != nil {
+		return err
+	}
+	links, err := s.ListConcordanceLinks()
+	if err != nil {
+		return err
+	}
+
+	// Describe the images the rows refer to, and hash the content as it will
+	// be served.
+	d := &dataset{
+		authors:     authors,
+		resources:   resources,
+		sections:    sections,
+		items:       items,
+		taxonomy:    tax,
+		concordance: links,
+	}
+	images := newImageIndex(s.opts, d)
+	d = d.withImages(images)
+
+	var prev *Version
+	if old := s.indexes(); old != nil {
+		prev = &old.version
+	}
+	ix := &sqliteIndexes{
+		images:  images,
+		version: newVersion(d, prev),
+		slugs:   newSlugIndex(resources, sections, items),
+		search:  newSearchIndex(resources, sections, items),
+		suggest: newSuggestTrie(sections, items),
+		tags:    taxonomy.NewIndex(tax, sections, items),
+		graph:   techgraph.New(items),
+	}
+
+	// The trees are read through the new generation's images.
+	ix.trees, err = buildResourceTrees(s.withIndexes(ix), resources)
+	if err != nil {
+		return err
+	}
+	ix.orders = buildReadingOrders(ix.trees)
+	s.gen.Store(ix)
+	return nil
+}
+
+// --- Technique graph ---
+
+// ItemGraph returns the items within depth relations of an item, or nil if
+// the item does not exist.
+func (s *SQLiteStore) ItemGraph(id, depth int) (*techgraph.Graph, error) {
+	ix := s.indexes().graph
+	if !ix.Has(id) {
+		return nil, nil
+	}
+	g := ix.Neighbourhood(id, depth)
+	return &g, nil
+}
+
+// SectionGraph returns the items of a section and the relations between them.
+func (s *SQLiteStore) SectionGraph(sectionID int) (*techgraph.Graph, error) {
+	g := s.indexes().graph.Section(sectionID)
+	return &g, nil
+}
+
+// --- Concordance ---
+
+// ListConcordanceLinks returns every concordance link in file order.
+func (s *SQLiteStore) ListConcordanceLinks() ([]models.ConcordanceLink, error) {
+	rows, err := s.db.Query(`SELECT item_id, related_item_id, type, note FROM concordance_links ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ConcordanceLink{}
+	for rows.Next() {
+		var l models.ConcordanceLink
+		if err := rows.Scan(&l.ItemID, &l.RelatedItemID, &l.Type, &l.Note); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// --- Taxonomy ---
+
+// TagSummary returns every taxonomy term with the number of items tagged with it.
+func (s *SQLiteStore) TagSummary() (*taxonomy.Summary, error) {
+	return s.indexes().tags.Summary(), nil
+}
+
+// ListItemsByTags returns a page of items whose effective tags match filter,
+// ordered by ID, and the total number of matches.
+func (s *SQLiteStore) ListItemsByTags(filter taxonomy.Filter, params pagination.Params) ([]TaggedItem, int, error) {
+	ix := s.indexes().tags
+	ids := ix.Match(filter)
+
+	page := paginate(ids, params)
+	if len(page) == 0 {
+		return nil, len(ids), nil
+	}
+
+	items := make([]TaggedItem, 0, len(page))
+	for _, id := range page {
+		item, err := s.GetItemByID(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if item == nil {
+			return nil, 0, fmt.Errorf("item %d disappeared from the database", id)
+		}
+		items = append(items, TaggedItem{Item: *item, EffectiveTags: ix.Tags(id)})
+	}
+	return items, len(ids), nil
+}
+
+func (s *SQLiteStore) loadTaxonomy() (*taxonomy.Taxonomy, error) {
+	rows, err := s.db.Query(`SELECT facet, slug, name FROM taxonomy_terms ORDER BY facet, position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tax := &taxonomy.Taxonomy{}
+	for rows.Next() {
+		var (
+			facet string
+			term  taxonomy.Term
+		)
+		if err := rows.Scan(&facet, &term.Slug, &term.Name); err != nil {
+			return nil, err
+		}
+		switch facet {
+		case taxonomy.FacetWeapon:
+			tax.Weapons = append(tax.Weapons, term)
+		case taxonomy.FacetGuard:
+			tax.Guards = append(tax.Guards, term)
+		case taxonomy.FacetAction:
+			tax.Actions = append(tax.Actions, term)
+		case taxonomy.FacetArmour:
+			tax.Armour = append(tax.Armour, term)
+		}
+	}
+	return tax, rows.Err()
+}
+
+// --- Version ---
+
+// Version returns the content version computed by the last rebuildIndexes.
+func (s *SQLiteStore) Version() (Version, error) {
+	return s.indexes().version, nil
+}
+
+// --- Scanning ---
+
+func (s *SQLiteStore) indexes() *sqliteIndexes {
+	return s.gen.Load()
+}
+
+// imageIndex returns the image index built with the other indexes.
+func (s *SQLiteStore) imageIndex() *imageIndex {
+	return s.indexes().images
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAuthor(row scanner, images *imageIndex) (*models.Author, error) {
+	var (
+		a         models.Author
+		birthYear sql.NullInt64
+		deathYear sql.NullInt64
+		imageURL  sql.NullString
+	)
+	if err := row.Scan(&a.ID, &a.Name, &a.Bio, &birthYear, &deathYear, &imageURL); err != nil {
+		return nil, err
+	}
+	a.BirthYear = nullIntPtr(birthYear)
+	a.DeathYear = nullIntPtr(deathYear)
+	a.ImageURL = nullStringPtr(imageURL)
+	images.author(&a)
+	return &a, nil
+}
+
+func scanResourceWithAuthor(row scanner, images *imageIndex) (*ResourceWithAuthor, error) {
+	var (
+		rwa             ResourceWithAuthor
+		authorID        sql.NullInt64
+		publicationYear sql.NullInt64
+		coverImageURL   sql.NullString
+		formerSlugs     sql.NullString
+	)
+	if err := row.Scan(&rwa.ID, &authorID, &rwa.Title, &rwa.Description,
+		&publicationYear, &coverImageURL, &rwa.AuthorName, &rwa.Slug, &formerSlugs); err != nil {
+		return nil, err
+	}
+	rwa.AuthorID = nullIntPtr(authorID)
+	rwa.PublicationYear = nullIntPtr(publicationYear)
+	rwa.CoverImageURL = nullStringPtr(coverImageURL)
+	images.resource(&rwa.Resource)
+	rwa.Slug = resourceSlug(rwa.Resource)
+	var err error
+	if rwa.FormerSlugs, err = nullSlugs(formerSlugs); err != nil {
+		return nil, fmt.Errorf("resource %d: %w", rwa.ID, err)
+	}
+	return &rwa, nil
+}
+
+func scanSection(row scanner) (*models.Section, error) {
+	var (
+		sec         models.Section
+		parentID    sql.NullInt64
+		tags        sql.NullString
+		formerSlugs sql.NullString
+	)
+	if err := row.Scan(&sec.ID, &sec.ResourceID, &parentID, &sec.Kind,
+		&sec.Title, &sec.Description, &sec.Position, &tags, &sec.Slug, &formerSlugs); err != nil {
+		return nil, err
+	}
+	sec.ParentID = nullIntPtr(parentID)
+	sec.Slug = sectionSlug(sec)
+	var err error
+	if sec.Tags, err = nullTags(tags); err != nil {
+		return nil, fmt.Errorf("section %d: %w", sec.ID, err)
+	}
+	if sec.FormerSlugs, err = nullSlugs(formerSlugs); err != nil {
+		return nil, fmt.Errorf("section %d: %w", sec.ID, err)
+	}
+	return &sec, nil
+}
+
+func scanItem(row scanner, images *imageIndex) (*models.Item, error) {
+	var (
+		item        models.Item
+		attributes  sql.NullString
+		tags        sql.NullString
+		formerSlugs sql.NullString
+	)
+	if err := row.Scan(&item.ID, &item.SectionID, &item.Kind, &item.Title,
+		&item.Description, &item.Position, &attributes, &tags, &item.Slug, &formerSlugs); err != nil {
+		return nil, err
+	}
+	if attributes.Valid {
+		item.Attributes = json.RawMessage(attributes.String)
+	}
+	images.item(&item)
+	item.Slug = itemSlug(item)
+	var err error
+	if item.Tags, err = nullTags(tags); err != nil {
+		return nil, fmt.Errorf("item %d: %w", item.ID, err)
+	}
+	if item.FormerSlugs, err = nullSlugs(formerSlugs); err != nil {
+		return nil, fmt.Errorf("item %d: %w", item.ID, err)
+	}
+	return &item, nil
+}
+
+func nullTags(v sql.NullString) (*models.Tags, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	var tags models.Tags
+	if err := json.Unmarshal([]byte(v.String), &tags); err != nil {
+		return nil, fmt.Errorf("parsing tags: %w", err)
+	}
+	return &tags, nil
+}
+
+func nullSlugs(v sql.NullString) ([]string, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	var slugs []string
+	if err := json.Unmarshal([]byte(v.String), &slugs); err != nil {
+		return nil, fmt.Errorf("parsing former slugs: %w", err)
+	}
+	return slugs, nil
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
+func nullStringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
@@ -4,255 +4,663 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"sort"
+	"sync/atomic"
 
 	"hema-lessons/internal/models"
 	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/search"
+	"hema-lessons/internal/suggest"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/techgraph"
 )
 
 //go:embed data
 var dataFS embed.FS
 
+// Data file names, relative to the root of the data directory.
+const (
+	authorsFile     = "authors.json"
+	resourcesFile   = "resources.json"
+	sectionsFile    = "sections.json"
+	itemsFile       = "items.json"
+	taxonomyFile    = "taxonomy.json"
+	concordanceFile = "concordance.json"
+)
+
 // ResourceWithAuthor combines a Resource with its Author's name.
 type ResourceWithAuthor struct {
 	models.Resource
 	AuthorName string `json:"author_name,omitempty"`
 }
 
-// Store holds all application data in memory, loaded from embedded JSON files.
+// TaggedItem is an item with the tags it inherits from its section and the
+// section's ancestors merged into its own.
+type TaggedItem struct {
+	models.Item
+	EffectiveTags models.Tags `json:"effective_tags"`
+}
+
+// Store holds all application data in memory, loaded from JSON files.
+// It is the default ContentRepository implementation.
+//
+// The data lives in an immutable snapshot that Reload swaps atomically, so a
+// lookup that is already running keeps reading the snapshot it started with.
 type Store struct {
+	source fs.FS
+	opts   options
+	snap   atomic.Pointer[snapshot]
+}
+
+// snapshot is one consistent, read-only view of the content.
+type snapshot struct {
 	authors   map[int]models.Author
 	resources map[int]models.Resource
 	sections  map[int]models.Section
 	items     map[int]models.Item
+
+	concordance []models.ConcordanceLink
+
+	// loaded is the content as parsed, with slugs but before images are
+	// derived and their URLs rewritten; it is what ImportFrom copies.
+	loaded *dataset
+
+	version  Version
+	trees    map[int]*ResourceTree
+	orders   map[int]*readingOrder
+	slugs    *slugIndex
+	search   *search.Index
+	suggest  *suggest.Trie
+	taxonomy *taxonomy.Index
+	graph    *techgraph.Index
 }
 
 // New creates a Store by parsing the embedded JSON data files.
-func New() (*Store, error) {
-	s := &Store{}
+func New(opts ...Option) (*Store, error) {
+	return Load(EmbeddedData(), opts...)
+}
 
-	if err := s.loadAuthors(); err != nil {
-		return nil, fmt.Errorf("loading authors: %w", err)
+// EmbeddedData returns the JSON data files compiled into the binary.
+func EmbeddedData() fs.FS {
+	sub, err := fs.Sub(dataFS, "data")
+	if err != nil {
+		panic(err)
 	}
-	if err := s.loadResources(); err != nil {
-		return nil, fmt.Errorf("loading resources: %w", err)
+	return sub
+}
+
+// Load creates a Store by parsing the JSON data files at the root of fsys.
+func Load(fsys fs.FS, opts ...Option) (*Store, error) {
+	o := newOptions(opts)
+	snap, err := loadSnapshot(fsys, o)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.loadSections(); err != nil {
-		return nil, fmt.Errorf("loading sections: %w", err)
+
+	s := &Store{source: fsys, opts: o}
+	s.snap.Store(snap)
+	return s, nil
+}
+
+// Reload re-reads the data files the Store was loaded from and swaps them in.
+// On error the current snapshot is kept.
+func (s *Store) Reload() error {
+	if s.source == nil {
+		return fmt.Errorf("store was not loaded from files")
 	}
-	if err := s.loadItems(); err != nil {
-		return nil, fmt.Errorf("loading items: %w", err)
+
+	snap, err := loadSnapshot(s.source, s.opts)
+	if err != nil {
+		return err
+	}
+	if prev := s.current(); prev.version.Hash == snap.version.Hash {
+		snap.version.LoadedAt = prev.version.LoadedAt
 	}
 
-	return s, nil
+	s.snap.Store(snap)
+	return nil
 }
 
 // NewFromData creates a Store from pre-built data (useful for testing).
+// The data is not validated. A nil taxonomy has no terms. It panics if the
+// resource trees cannot be built.
 func NewFromData(
 	authors []models.Author,
 	resources []models.Resource,
 	sections []models.Section,
 	items []models.Item,
+	concordance []models.ConcordanceLink,
+	tax *taxonomy.Taxonomy,
 ) *Store {
-	s := &Store{
-		authors:   make(map[int]models.Author, len(authors)),
-		resources: make(map[int]models.Resource, len(resources)),
-		sections:  make(map[int]models.Section, len(sections)),
-		items:     make(map[int]models.Item, len(items)),
+	snap, err := newSnapshot(&dataset{
+		authors:     authors,
+		resources:   resources,
+		sections:    sections,
+		items:       items,
+		concordance: concordance,
+		taxonomy:    tax,
+	}, options{})
+	if err != nil {
+		panic(fmt.Sprintf("store.NewFromData: %v", err))
 	}
+	s := &Store{}
+	s.snap.Store(snap)
+	return s
+}
 
-	for _, a := range authors {
-		s.authors[a.ID] = a
+func (s *Store) current() *snapshot {
+	return s.snap.Load()
+}
+
+// View returns a repository pinned to the current snapshot. A request that
+// makes several lookups takes one view and makes them all through it, so a
+// reload in between cannot mix content from two snapshots.
+func (s *Store) View() ContentRepository {
+	return snapshotView(s.current())
+}
+
+// snapshotView returns a Store that only reads snap. It cannot be reloaded.
+func snapshotView(snap *snapshot) *Store {
+	v := &Store{}
+	v.snap.Store(snap)
+	return v
+}
+
+// --- Authors ---
+
+// ListAuthors returns a paginated list of authors, ordered by name.
+func (s *Store) ListAuthors(params pagination.Params) ([]models.Author, int, error) {
+	snap := s.current()
+	totalCount := len(snap.authors)
+	if totalCount == 0 {
+		return nil, 0, nil
 	}
-	for _, r := range resources {
-		s.resources[r.ID] = r
+
+	authors := make([]models.Author, 0, totalCount)
+	for _, a := range snap.authors {
+		authors = append(authors, a)
 	}
-	for _, sec := range sections {
-		s.sections[sec.ID] = sec
+
+	sort.SliceStable(authors, func(i, j int) bool {
+		if authors[i].Name != authors[j].Name {
+			return authors[i].Name < authors[j].Name
+		}
+		return authors[i].ID < authors[j].ID
+	})
+
+	page := paginate(authors, params)
+	if len(page) == 0 {
+		return nil, totalCount, nil
 	}
-	for _, i := range items {
-		s.items[i.ID] = i
+
+	return page, totalCount, nil
+}
+
+// GetAuthorByID returns a single author, or nil if not found.
+func (s *Store) GetAuthorByID(id int) (*models.Author, error) {
+	snap := s.current()
+	a, ok := snap.authors[id]
+	if !ok {
+		return nil, nil
 	}
+	return &a, nil
+}
 
-	return s
+// ListAllAuthors returns every author with their relations, ordered by name.
+func (s *Store) ListAllAuthors() ([]models.Author, error) {
+	snap := s.current()
+	authors := make([]models.Author, 0, len(snap.authors))
+	for _, a := range snap.authors {
+		authors = append(authors, a)
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Name != authors[j].Name {
+			return authors[i].Name < authors[j].Name
+		}
+		return authors[i].ID < authors[j].ID
+	})
+
+	return authors, nil
 }
 
 // --- Resources ---
 
 // ListResources returns a paginated list of resources with their author names, ordered by title.
-func (s *Store) ListResources(params pagination.Params) ([]ResourceWithAuthor, int) {
-	totalCount := len(s.resources)
+func (s *Store) ListResources(params pagination.Params) ([]ResourceWithAuthor, int, error) {
+	snap := s.current()
+	totalCount := len(snap.resources)
 	if totalCount == 0 {
-		return nil, 0
+		return nil, 0, nil
 	}
 
 	resources := make([]ResourceWithAuthor, 0, totalCount)
-	for _, r := range s.resources {
+	for _, r := range snap.resources {
 		rwa := ResourceWithAuthor{Resource: r}
 		if r.AuthorID != nil {
-			if author, ok := s.authors[*r.AuthorID]; ok {
+			if author, ok := snap.authors[*r.AuthorID]; ok {
 				rwa.AuthorName = author.Name
 			}
 		}
 		resources = append(resources, rwa)
 	}
 
-	sort.Slice(resources, func(i, j int) bool {
-		return resources[i].Title < resources[j].Title
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Title != resources[j].Title {
+			return resources[i].Title < resources[j].Title
+		}
+		return resources[i].ID < resources[j].ID
 	})
 
-	start := params.Offset
-	if start > totalCount {
-		start = totalCount
-	}
-	end := start + params.PageSize
-	if end > totalCount {
-		end = totalCount
-	}
-
-	page := resources[start:end]
+	page := paginate(resources, params)
 	if len(page) == 0 {
-		return nil, totalCount
+		return nil, totalCount, nil
 	}
 
-	return page, totalCount
+	return page, totalCount, nil
 }
 
 // GetResourceByID returns a single resource with its author name, or nil if not found.
-func (s *Store) GetResourceByID(id int) *ResourceWithAuthor {
-	r, ok := s.resources[id]
+func (s *Store) GetResourceByID(id int) (*ResourceWithAuthor, error) {
+	snap := s.current()
+	r, ok := snap.resources[id]
 	if !ok {
-		return nil
+		return nil, nil
 	}
 
 	rwa := &ResourceWithAuthor{Resource: r}
 	if r.AuthorID != nil {
-		if author, ok := s.authors[*r.AuthorID]; ok {
+		if author, ok := snap.authors[*r.AuthorID]; ok {
 			rwa.AuthorName = author.Name
 		}
 	}
-	return rwa
+	return rwa, nil
 }
 
 // ResourceExists returns true if a resource with the given ID exists.
-func (s *Store) ResourceExists(id int) bool {
-	_, ok := s.resources[id]
-	return ok
+func (s *Store) ResourceExists(id int) (bool, error) {
+	snap := s.current()
+	_, ok := snap.resources[id]
+	return ok, nil
+}
+
+// ListResourcesByAuthorID returns the resources written by an author, ordered by
+// publication year, title and ID. Resources without a year sort last. An
+// author without resources has an empty, non-nil list.
+func (s *Store) ListResourcesByAuthorID(authorID int) ([]ResourceWithAuthor, error) {
+	snap := s.current()
+	author, ok := snap.authors[authorID]
+	if !ok {
+		return nil, nil
+	}
+
+	resources := []ResourceWithAuthor{}
+	for _, r := range snap.resources {
+		if r.AuthorID != nil && *r.AuthorID == authorID {
+			resources = append(resources, ResourceWithAuthor{Resource: r, AuthorName: author.Name})
+		}
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i].PublicationYear, resources[j].PublicationYear
+		switch {
+		case a != nil && b != nil && *a != *b:
+			return *a < *b
+		case (a == nil) != (b == nil):
+			return a != nil
+		case resources[i].Title != resources[j].Title:
+			return resources[i].Title < resources[j].Title
+		}
+		return resources[i].ID < resources[j].ID
+	})
+
+	return resources, nil
+}
+
+// GetResourceBySlug returns the resource with a current or former slug, or
+// nil if there is none. A Slug that differs from slug means slug is former.
+func (s *Store) GetResourceBySlug(slug string) (*ResourceWithAuthor, error) {
+	snap := s.current()
+	r, ok := snap.resources[snap.slugs.resource(slug)]
+	if !ok {
+		return nil, nil
+	}
+
+	rwa := &ResourceWithAuthor{Resource: r}
+	if r.AuthorID != nil {
+		if author, ok := snap.authors[*r.AuthorID]; ok {
+			rwa.AuthorName = author.Name
+		}
+	}
+	return rwa, nil
+}
+
+// ResourceTree returns the precomputed table of contents of a resource, or
+// nil if the resource does not exist. Callers must not modify it; use Prune
+// to trim it.
+func (s *Store) ResourceTree(resourceID int) (*ResourceTree, error) {
+	return s.current().trees[resourceID], nil
+}
+
+// readingOrder returns the precomputed reading order of a resource, or nil
+// if it does not exist.
+func (s *Store) readingOrder(resourceID int) *readingOrder {
+	return s.current().orders[resourceID]
 }
 
 // --- Sections ---
 
 // ListRootSectionsByResourceID returns top-level sections (parent_id is nil) for a given resource, ordered by position.
-func (s *Store) ListRootSectionsByResourceID(resourceID int) []models.Section {
+func (s *Store) ListRootSectionsByResourceID(resourceID int) ([]models.Section, error) {
+	snap := s.current()
 	var sections []models.Section
-	for _, sec := range s.sections {
+	for _, sec := range snap.sections {
 		if sec.ResourceID == resourceID && sec.ParentID == nil {
 			sections = append(sections, sec)
 		}
 	}
 
-	sort.Slice(sections, func(i, j int) bool {
-		return sections[i].Position < sections[j].Position
+	sort.SliceStable(sections, func(i, j int) bool {
+		if sections[i].Position != sections[j].Position {
+			return sections[i].Position < sections[j].Position
+		}
+		return sections[i].ID < sections[j].ID
 	})
 
-	return sections
+	return sections, nil
 }
 
 // GetSectionByID returns a single section, or nil if not found.
-func (s *Store) GetSectionByID(id int) *models.Section {
-	sec, ok := s.sections[id]
+func (s *Store) GetSectionByID(id int) (*models.Section, error) {
+	snap := s.current()
+	sec, ok := snap.sections[id]
 	if !ok {
-		return nil
+		return nil, nil
 	}
-	return &sec
+	return &sec, nil
+}
+
+// GetSectionBySlug returns the section of a resource with a current or
+// former slug, or nil if there is none.
+func (s *Store) GetSectionBySlug(resourceID int, slug string) (*models.Section, error) {
+	snap := s.current()
+	sec, ok := snap.sections[snap.slugs.section(resourceID, slug)]
+	if !ok {
+		return nil, nil
+	}
+	return &sec, nil
 }
 
 // ListChildSections returns direct child sections of a given parent section, ordered by position.
-func (s *Store) ListChildSections(parentID int) []models.Section {
+func (s *Store) ListChildSections(parentID int) ([]models.Section, error) {
+	snap := s.current()
 	var sections []models.Section
-	for _, sec := range s.sections {
+	for _, sec := range snap.sections {
 		if sec.ParentID != nil && *sec.ParentID == parentID {
 			sections = append(sections, sec)
 		}
 	}
 
-	sort.Slice(sections, func(i, j int) bool {
-		return sections[i].Position < sections[j].Position
+	sort.SliceStable(sections, func(i, j int) bool {
+		if sections[i].Position != sections[j].Position {
+			return sections[i].Position < sections[j].Position
+		}
+		return sections[i].ID < sections[j].ID
 	})
 
-	return sections
+	return sections, nil
 }
 
 // --- Items ---
 
 // ListItemsBySectionID returns items for a given section, ordered by position.
-func (s *Store) ListItemsBySectionID(sectionID int) []models.Item {
+func (s *Store) ListItemsBySectionID(sectionID int) ([]models.Item, error) {
+	snap := s.current()
 	var items []models.Item
-	for _, item := range s.items {
+	for _, item := range snap.items {
 		if item.SectionID == sectionID {
 			items = append(items, item)
 		}
 	}
 
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Position < items[j].Position
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].ID < items[j].ID
 	})
 
-	return items
+	return items, nil
+}
+
+// GetItemByID returns a single item, or nil if not found.
+func (s *Store) GetItemByID(id int) (*models.Item, error) {
+	snap := s.current()
+	item, ok := snap.items[id]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+// GetItemBySlug returns the item of a resource with a current or former slug,
+// or nil if there is none.
+func (s *Store) GetItemBySlug(resourceID int, slug string) (*models.Item, error) {
+	snap := s.current()
+	item, ok := snap.items[snap.slugs.item(resourceID, slug)]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+// --- Search ---
+
+// Search returns every resource, section and item matching query, best first.
+func (s *Store) Search(query string) ([]search.Result, error) {
+	return s.current().search.Search(query), nil
+}
+
+// Suggest returns up to limit section and item titles starting with prefix.
+func (s *Store) Suggest(prefix string, limit int) ([]suggest.Entry, error) {
+	return s.current().suggest.Suggest(prefix, limit), nil
+}
+
+// --- Technique graph ---
+
+// ItemGraph returns the items within depth relations of an item, or nil if
+// the item does not exist.
+func (s *Store) ItemGraph(id, depth int) (*techgraph.Graph, error) {
+	ix := s.current().graph
+	if !ix.Has(id) {
+		return nil, nil
+	}
+	g := ix.Neighbourhood(id, depth)
+	return &g, nil
+}
+
+// SectionGraph returns the items of a section and the relations between them.
+func (s *Store) SectionGraph(sectionID int) (*techgraph.Graph, error) {
+	g := s.current().graph.Section(sectionID)
+	return &g, nil
+}
+
+// --- Concordance ---
+
+// ListConcordanceLinks returns every concordance link in file order.
+func (s *Store) ListConcordanceLinks() ([]models.ConcordanceLink, error) {
+	snap := s.current()
+	links := make([]models.ConcordanceLink, len(snap.concordance))
+	copy(links, snap.concordance)
+	return links, nil
+}
+
+// --- Taxonomy ---
+
+// TagSummary returns every taxonomy term with the number of items tagged with it.
+func (s *Store) TagSummary() (*taxonomy.Summary, error) {
+	return s.current().taxonomy.Summary(), nil
+}
+
+// ListItemsByTags returns a page of items whose effective tags match filter,
+// ordered by ID, and the total number of matches.
+func (s *Store) ListItemsByTags(filter taxonomy.Filter, params pagination.Params) ([]TaggedItem, int, error) {
+	snap := s.current()
+	ids := snap.taxonomy.Match(filter)
+
+	page := paginate(ids, params)
+	if len(page) == 0 {
+		return nil, len(ids), nil
+	}
+
+	items := make([]TaggedItem, 0, len(page))
+	for _, id := range page {
+		items = append(items, TaggedItem{Item: snap.items[id], EffectiveTags: snap.taxonomy.Tags(id)})
+	}
+	return items, len(ids), nil
+}
+
+// paginate returns the part of sorted that falls on the page described by params.
+func paginate[T any](sorted []T, params pagination.Params) []T {
+	start := params.Offset
+	if start > len(sorted) {
+		start = len(sorted)
+	}
+	end := start + params.PageSize
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	return sorted[start:end]
+}
+
+// --- Version ---
+
+// Version returns the version of the current snapshot.
+func (s *Store) Version() (Version, error) {
+	return s.current().version, nil
 }
 
 // --- Data loading ---
 
-func (s *Store) loadAuthors() error {
-	var items []models.Author
-	if err := loadJSON("data/authors.json", &items); err != nil {
-		return err
+// dataset is the content as parsed from the data files, before it is indexed.
+type dataset struct {
+	authors   []models.Author
+	resources []models.Resource
+	sections  []models.Section
+	items     []models.Item
+	taxonomy  *taxonomy.Taxonomy
+
+	concordance []models.ConcordanceLink
+}
+
+func loadSnapshot(fsys fs.FS, o options) (*snapshot, error) {
+	d, err := readDataset(fsys)
+	if err != nil {
+		return nil, err
 	}
-	s.authors = make(map[int]models.Author, len(items))
-	for _, item := range items {
-		s.authors[item.ID] = item
+	if err := d.validate(); err != nil {
+		return nil, err
 	}
-	return nil
+	return newSnapshot(d, o)
 }
 
-func (s *Store) loadResources() error {
-	var items []models.Resource
-	if err := loadJSON("data/resources.json", &items); err != nil {
-		return err
+func readDataset(fsys fs.FS) (*dataset, error) {
+	d := &dataset{}
+
+	if err := loadJSON(fsys, authorsFile, &d.authors); err != nil {
+		return nil, fmt.Errorf("loading authors: %w", err)
 	}
-	s.resources = make(map[int]models.Resource, len(items))
-	for _, item := range items {
-		s.resources[item.ID] = item
+	if err := loadJSON(fsys, resourcesFile, &d.resources); err != nil {
+		return nil, fmt.Errorf("loading resources: %w", err)
 	}
-	return nil
+	if err := loadJSON(fsys, sectionsFile, &d.sections); err != nil {
+		return nil, fmt.Errorf("loading sections: %w", err)
+	}
+	if err := loadJSON(fsys, itemsFile, &d.items); err != nil {
+		return nil, fmt.Errorf("loading items: %w", err)
+	}
+	if err := loadJSON(fsys, concordanceFile, &d.concordance); err != nil {
+		return nil, fmt.Errorf("loading concordance: %w", err)
+	}
+	d.taxonomy = &taxonomy.Taxonomy{}
+	if err := loadJSON(fsys, taxonomyFile, d.taxonomy); err != nil {
+		return nil, fmt.Errorf("loading taxonomy: %w", err)
+	}
+
+	return d, nil
 }
 
-func (s *Store) loadSections() error {
-	var items []models.Section
-	if err := loadJSON("data/sections.json", &items); err != nil {
-		return err
+func newSnapshot(d *dataset, o options) (*snapshot, error) {
+	loaded := d.withSlugs()
+	d = loaded.withImages(newImageIndex(o, loaded))
+	snap := &snapshot{
+		authors:   make(map[int]models.Author, len(d.authors)),
+		resources: make(map[int]models.Resource, len(d.resources)),
+		sections:  make(map[int]models.Section, len(d.sections)),
+		items:     make(map[int]models.Item, len(d.items)),
+
+		concordance: d.concordance,
+		loaded:      loaded,
 	}
-	s.sections = make(map[int]models.Section, len(items))
-	for _, item := range items {
-		s.sections[item.ID] = item
+
+	for _, a := range d.authors {
+		snap.authors[a.ID] = a
 	}
-	return nil
+	for _, r := range d.resources {
+		snap.resources[r.ID] = r
+	}
+	for _, sec := range d.sections {
+		snap.sections[sec.ID] = sec
+	}
+	for _, i := range d.items {
+		snap.items[i.ID] = i
+	}
+
+	snap.version = newVersion(d, nil)
+	snap.slugs = newSlugIndex(d.resources, d.sections, d.items)
+	snap.search = newSearchIndex(d.resources, d.sections, d.items)
+	snap.suggest = newSuggestTrie(d.sections, d.items)
+	snap.taxonomy = taxonomy.NewIndex(d.taxonomy, d.sections, d.items)
+	snap.graph = techgraph.New(d.items)
+
+	// The trees are built through the snapshot's own lookups. Should that
+	// ever fail, the load is rejected rather than served without trees.
+	trees, err := buildResourceTrees(snapshotView(snap), d.resources)
+	if err != nil {
+		return nil, fmt.Errorf("building resource trees: %w", err)
+	}
+	snap.trees = trees
+	snap.orders = buildReadingOrders(trees)
+
+	return snap, nil
 }
 
-func (s *Store) loadItems() error {
-	var items []models.Item
-	if err := loadJSON("data/items.json", &items); err != nil {
-		return err
+// withSlugs returns a copy of the dataset in which every resource, section
+// and item has its slug set, derived from its title where the data leaves it
+// out.
+func (d *dataset) withSlugs() *dataset {
+	c := *d
+	c.resources = make([]models.Resource, len(d.resources))
+	for i, r := range d.resources {
+		r.Slug = resourceSlug(r)
+		c.resources[i] = r
 	}
-	s.items = make(map[int]models.Item, len(items))
-	for _, item := range items {
-		s.items[item.ID] = item
+	c.sections = make([]models.Section, len(d.sections))
+	for i, sec := range d.sections {
+		sec.Slug = sectionSlug(sec)
+		c.sections[i] = sec
 	}
-	return nil
+	c.items = make([]models.Item, len(d.items))
+	for i, item := range d.items {
+		item.Slug = itemSlug(item)
+		c.items[i] = item
+	}
+	return &c
 }
 
-func loadJSON(path string, dest interface{}) error {
-	data, err := dataFS.ReadFile(path)
+func loadJSON(fsys fs.FS, path string, dest interface{}) error {
+	data, err := fs.ReadFile(fsys, path)
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", path, err)
 	}
@@ -0,0 +1,712 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"hema-lessons/internal/assets"
+	"hema-lessons/internal/imaging"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/testutil"
+)
+
+func TestNew_LoadsEmbeddedData(t *testing.T) {
+	s, err := store.New()
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+
+	resource, err := s.GetResourceByID(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resource == nil || resource.Title != "Fior di Battaglia" {
+		t.Errorf("expected resource 2 to be Fior di Battaglia, got %+v", resource)
+	}
+}
+
+func TestStore_Reload(t *testing.T) {
+	dir := testutil.WriteDataDir(t)
+
+	s, err := store.Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("failed to load data dir: %v", err)
+	}
+
+	resources := testutil.TestResources()
+	resources[0].Title = "Book A (revised)"
+	testutil.WriteDataFile(t, dir, "resources.json", resources)
+
+	if err := s.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	assertResourceTitle(t, s, 1, "Book A (revised)")
+
+	if err := os.WriteFile(filepath.Join(dir, "resources.json"), []byte(`[{"id": 1,`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reload(); err == nil {
+		t.Fatal("expected reload of malformed JSON to fail")
+	}
+	assertResourceTitle(t, s, 1, "Book A (revised)")
+}
+
+func TestStore_View_KeepsSnapshotAcrossReload(t *testing.T) {
+	dir := testutil.WriteDataDir(t)
+
+	s, err := store.Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("failed to load data dir: %v", err)
+	}
+	view := s.View()
+
+	// Move item 6 out of section 6 and drop the section.
+	items := testutil.TestItems()
+	for i := range items {
+		if items[i].ID == 6 {
+			items[i].SectionID = 3
+		}
+	}
+	var sections []models.Section
+	for _, sec := range testutil.TestSections() {
+		if sec.ID != 6 {
+			sections = append(sections, sec)
+		}
+	}
+	testutil.WriteDataFile(t, dir, "items.json", items)
+	testutil.WriteDataFile(t, dir, "sections.json", sections)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	detail, err := store.GetItemDetail(view, 6)
+	if err != nil || detail == nil {
+		t.Fatalf("expected item 6 from the view, got %+v, %v", detail, err)
+	}
+	if detail.Section.ID != 6 || len(detail.Breadcrumb) != 3 {
+		t.Errorf("expected item 6 under section 6 as loaded, got section %d, breadcrumb %+v", detail.Section.ID, detail.Breadcrumb)
+	}
+	if _, err := store.ItemNavigation(view, &detail.Item); err != nil {
+		t.Errorf("expected navigation from the view, got %v", err)
+	}
+
+	current, err := store.GetItemDetail(s, 6)
+	if err != nil || current == nil || current.Section.ID != 3 {
+		t.Errorf("expected the store to serve item 6 under section 3, got %+v, %v", current, err)
+	}
+}
+
+// treeOnly hides the store's precomputed reading orders, so navigation is
+// worked out from ResourceTree on every call.
+type treeOnly struct {
+	store.ContentRepository
+}
+
+func TestItemNavigation_PrecomputedMatchesTree(t *testing.T) {
+	repos := map[string]store.ContentRepository{
+		"memory": testutil.NewTestStore(),
+		"sqlite": testutil.NewTestSQLiteStore(t),
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			for _, item := range testutil.TestItems() {
+				got, err := store.ItemNavigation(repo, &item)
+				if err != nil {
+					t.Fatalf("item %d: %v", item.ID, err)
+				}
+				want, err := store.ItemNavigation(treeOnly{repo}, &item)
+				if err != nil {
+					t.Fatalf("item %d: %v", item.ID, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("item %d: expected %+v, got %+v", item.ID, want, got)
+				}
+			}
+			for _, sec := range testutil.TestSections() {
+				got, err := store.GetSectionDetail(repo, sec.ID)
+				if err != nil {
+					t.Fatalf("section %d: %v", sec.ID, err)
+				}
+				want, err := store.GetSectionDetail(treeOnly{repo}, sec.ID)
+				if err != nil {
+					t.Fatalf("section %d: %v", sec.ID, err)
+				}
+				if !reflect.DeepEqual(got.Navigation, want.Navigation) {
+					t.Errorf("section %d: expected %+v, got %+v", sec.ID, want.Navigation, got.Navigation)
+				}
+			}
+		})
+	}
+}
+
+// TestStore_ListTieBreaksByID checks that equal names and titles are ordered
+// by ID, as SQLite orders them, so pages do not depend on map order.
+func TestStore_ListTieBreaksByID(t *testing.T) {
+	authors := []models.Author{{ID: 3, Name: "Anonymous"}, {ID: 1, Name: "Anonymous"}, {ID: 2, Name: "Anonymous"}}
+	author, year := 1, 1452
+	resources := []models.Resource{
+		{ID: 3, AuthorID: &author, Title: "Fechtbuch", PublicationYear: &year},
+		{ID: 1, AuthorID: &author, Title: "Fechtbuch", PublicationYear: &year},
+		{ID: 2, AuthorID: &author, Title: "Fechtbuch", PublicationYear: &year},
+	}
+	s := store.NewFromData(authors, resources, nil, nil, nil, nil)
+
+	for range 20 {
+		byAuthor, err := s.ListResourcesByAuthorID(1)
+		if err != nil || len(byAuthor) != 3 || byAuthor[0].ID != 1 || byAuthor[1].ID != 2 || byAuthor[2].ID != 3 {
+			t.Fatalf("expected the author's resources in ID order, got %+v, %v", byAuthor, err)
+		}
+		for i := range 3 {
+			params := pagination.Params{Page: i + 1, PageSize: 1, Offset: i}
+			page, _, err := s.ListAuthors(params)
+			if err != nil || len(page) != 1 || page[0].ID != i+1 {
+				t.Fatalf("expected author %d on page %d, got %+v, %v", i+1, i+1, page, err)
+			}
+			resPage, _, err := s.ListResources(params)
+			if err != nil || len(resPage) != 1 || resPage[0].ID != i+1 {
+				t.Fatalf("expected resource %d on page %d, got %+v, %v", i+1, i+1, resPage, err)
+			}
+		}
+	}
+
+	// An author without works lists an empty array, not null, in both
+	// backends.
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "content.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.ImportFrom(s); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	for _, repo := range []store.ContentRepository{s, db} {
+		none, err := repo.ListResourcesByAuthorID(3)
+		if err != nil || none == nil || len(none) != 0 {
+			t.Errorf("%T: expected an empty, non-nil list, got %#v, %v", repo, none, err)
+		}
+	}
+}
+
+func TestStore_Version(t *testing.T) {
+	dir := testutil.WriteDataDir(t)
+
+	s, err := store.Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("failed to load data dir: %v", err)
+	}
+	first, err := s.Version()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Hash == "" || first.LoadedAt.IsZero() {
+		t.Fatalf("expected a hash and load time, got %+v", first)
+	}
+
+	if err := s.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	unchanged, _ := s.Version()
+	if unchanged != first {
+		t.Errorf("expected reloading unchanged content to keep version %+v, got %+v", first, unchanged)
+	}
+
+	items := testutil.TestItems()
+	items[0].Description = "Revised description"
+	testutil.WriteDataFile(t, dir, "items.json", items)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	changed, _ := s.Version()
+	if changed.Hash == first.Hash {
+		t.Error("expected the hash to change with the content")
+	}
+	if changed.LoadedAt.Before(first.LoadedAt) {
+		t.Errorf("expected load time to move forward, got %v after %v", changed.LoadedAt, first.LoadedAt)
+	}
+}
+
+func TestNew_DerivesImages(t *testing.T) {
+	s, err := store.New()
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+
+	resource, err := s.GetResourceByID(2)
+	if err != nil || resource == nil {
+		t.Fatalf("expected resource 2, got %+v, %v", resource, err)
+	}
+	cover := resource.CoverImage
+	if cover == nil || resource.CoverImageURL == nil || cover.URL != *resource.CoverImageURL {
+		t.Fatalf("expected the cover image to match cover_image_url, got %+v", cover)
+	}
+	if !strings.HasPrefix(cover.Srcset, cover.URL+"?w=320 320w, ") {
+		t.Errorf("unexpected srcset %q", cover.Srcset)
+	}
+
+	item, err := s.GetItemByID(1)
+	if err != nil || item == nil {
+		t.Fatalf("expected item 1, got %+v, %v", item, err)
+	}
+	historical, ok := item.Images["historical_image_url"]
+	if !ok || !strings.HasSuffix(historical.URL, "/historical.jpg") || historical.Srcset == "" {
+		t.Errorf("expected the historical image with a srcset, got %+v", item.Images)
+	}
+}
+
+func TestWithImages_DescribesReferencedImages(t *testing.T) {
+	var cover bytes.Buffer
+	if err := png.Encode(&cover, image.NewGray(image.Rect(0, 0, 300, 450))); err != nil {
+		t.Fatal(err)
+	}
+	// Only the cover exists; the items' historical images are missing.
+	catalog := imaging.NewCatalog(fstest.MapFS{
+		"books/fior-di-battaglia/cover/cover.jpg": &fstest.MapFile{Data: cover.Bytes()},
+	}, "/assets/")
+
+	mem, err := store.New(store.WithImages(catalog))
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "content.db"), store.WithImages(catalog))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.ImportFrom(mem); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	for name, repo := range map[string]store.ContentRepository{"memory": mem, "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			resource, err := repo.GetResourceByID(2)
+			if err != nil || resource == nil {
+				t.Fatalf("expected resource 2, got %+v, %v", resource, err)
+			}
+			img := resource.CoverImage
+			if img == nil || img.Width != 300 || img.Height != 450 || img.AspectRatio != 0.6667 {
+				t.Fatalf("expected the cover's dimensions, got %+v", img)
+			}
+			if img.BlurHash == "" || img.DominantColor != "#000000" {
+				t.Errorf("expected placeholders, got %+v", img)
+			}
+			if img.Srcset != img.URL+" 300w" {
+				t.Errorf("expected a srcset without enlarged variants, got %q", img.Srcset)
+			}
+
+			item, err := repo.GetItemByID(1)
+			if err != nil || item == nil {
+				t.Fatalf("expected item 1, got %+v, %v", item, err)
+			}
+			if historical := item.Images["historical_image_url"]; historical.URL == "" || historical.Width != 0 || historical.BlurHash != "" {
+				t.Errorf("expected a missing image without metadata, got %+v", historical)
+			}
+		})
+	}
+}
+
+func TestWithAssetManifest_RewritesImageURLs(t *testing.T) {
+	const (
+		coverURL      = "/assets/books/fior-di-battaglia/cover/cover.jpg"
+		historicalURL = "/assets/books/fior-di-battaglia/techniques/first-remedy-master-of-abrazare/historical.jpg"
+	)
+	manifest, err := assets.NewManifest(fstest.MapFS{
+		strings.TrimPrefix(coverURL, "/assets/"):      &fstest.MapFile{Data: []byte("cover")},
+		strings.TrimPrefix(historicalURL, "/assets/"): &fstest.MapFile{Data: []byte("plate")},
+	}, "/assets/", "books")
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+	hashedCover := manifest.URL(coverURL)
+	hashedHistorical := manifest.URL(historicalURL)
+
+	mem, err := store.New(store.WithAssetManifest(manifest))
+	if err != nil {
+		t.Fatalf("failed to load embedded data: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "content.db")
+	db, err := store.OpenSQLite(path, store.WithAssetManifest(manifest))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.ImportFrom(mem); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	for name, repo := range map[string]store.ContentRepository{"memory": mem, "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			resource, err := repo.GetResourceByID(2)
+			if err != nil || resource == nil {
+				t.Fatalf("expected resource 2, got %+v, %v", resource, err)
+			}
+			if resource.CoverImageURL == nil || *resource.CoverImageURL != hashedCover {
+				t.Errorf("expected cover_image_url %q, got %v", hashedCover, resource.CoverImageURL)
+			}
+			if resource.CoverImage == nil || resource.CoverImage.URL != hashedCover || !strings.HasPrefix(resource.CoverImage.Srcset, hashedCover+"?w=") {
+				t.Errorf("expected the cover image under its fingerprinted URL, got %+v", resource.CoverImage)
+			}
+
+			item, err := repo.GetItemByID(1)
+			if err != nil || item == nil {
+				t.Fatalf("expected item 1, got %+v, %v", item, err)
+			}
+			var attrs map[string]string
+			if err := json.Unmarshal(item.Attributes, &attrs); err != nil {
+				t.Fatalf("failed to decode attributes: %v", err)
+			}
+			if attrs["historical_image_url"] != hashedHistorical {
+				t.Errorf("expected historical_image_url %q, got %q", hashedHistorical, attrs["historical_image_url"])
+			}
+			if attrs["instructions"] == "" {
+				t.Error("expected the other attributes to be kept")
+			}
+			if got := item.Images["historical_image_url"].URL; got != hashedHistorical {
+				t.Errorf("expected image URL %q, got %q", hashedHistorical, got)
+			}
+		})
+	}
+
+	// The database keeps logical URLs, so it can be opened with another
+	// manifest, or none.
+	db.Close()
+	plain, err := store.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer plain.Close()
+	if resource, _ := plain.GetResourceByID(2); resource == nil || resource.CoverImageURL == nil || *resource.CoverImageURL != coverURL {
+		t.Errorf("expected the stored cover_image_url to be logical, got %+v", resource)
+	}
+}
+
+func TestStore_Reload_WithoutSource(t *testing.T) {
+	s := testutil.NewTestStore()
+	if err := s.Reload(); err == nil {
+		t.Error("expected reload of a store built from data to fail")
+	}
+}
+
+func TestStore_Watch(t *testing.T) {
+	dir := testutil.WriteDataDir(t)
+
+	s, err := store.Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("failed to load data dir: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s.Watch(ctx, dir); err != nil {
+		t.Fatalf("failed to watch data dir: %v", err)
+	}
+
+	resources := testutil.TestResources()
+	resources[0].Title = "Book A (watched)"
+	testutil.WriteDataFile(t, dir, "resources.json", resources)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if r, _ := s.GetResourceByID(1); r != nil && r.Title == "Book A (watched)" {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("store was not reloaded after the data file changed")
+}
+
+func assertResourceTitle(t *testing.T, s *store.Store, id int, want string) {
+	t.Helper()
+
+	r, err := s.GetResourceByID(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r == nil {
+		t.Fatalf("resource %d not found", id)
+	}
+	if r.Title != want {
+		t.Errorf("expected title %q, got %q", want, r.Title)
+	}
+}
+
+func TestLoad_RejectsInvalidReferences(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	attrs := json.RawMessage(`{"instructions":"Step 1"}`)
+
+	tests := []struct {
+		name     string
+		file     string
+		data     interface{}
+		expected []string
+	}{
+		{
+			name: "invalid author relations",
+			file: "authors.json",
+			data: append(testutil.TestAuthors(), models.Author{ID: 4, Name: "Test Author 4",
+				Relations: []models.AuthorRelation{
+					{Type: "rival_of", AuthorID: 1},
+					{Type: models.RelationTeacherOf, AuthorID: 4},
+					{Type: models.RelationGlossedBy, AuthorID: 42},
+					{Type: models.RelationTradition, AuthorID: 2},
+					{Type: models.RelationTradition, AuthorID: 2},
+				}}),
+			expected: []string{
+				`authors.json: id 4: unknown relation type "rival_of"`,
+				"authors.json: id 4: teacher_of relation points at the author itself",
+				"authors.json: id 4: glossed_by relation author_id 42 does not exist",
+				"authors.json: id 4: duplicate tradition relation to author 2",
+			},
+		},
+		{
+			name: "missing author",
+			file: "resources.json",
+			data: append(testutil.TestResources(), models.Resource{ID: 6, AuthorID: intPtr(42), Title: "Book F"}),
+			expected: []string{
+				"resources.json: id 6: author_id 42 does not exist",
+			},
+		},
+		{
+			name: "duplicate resource id",
+			file: "resources.json",
+			data: append(testutil.TestResources(), models.Resource{ID: 2, Title: "Book B again"}),
+			expected: []string{
+				"resources.json: id 2: duplicate id (entry #6)",
+			},
+		},
+		{
+			name: "missing resource and parent",
+			file: "sections.json",
+			data: append(testutil.TestSections(),
+				models.Section{ID: 7, ResourceID: 99, Kind: "chapter", Title: "Orphan", Position: 1},
+				models.Section{ID: 8, ResourceID: 1, ParentID: intPtr(77), Kind: "sub-chapter", Title: "Lost", Position: 1},
+			),
+			expected: []string{
+				"sections.json: id 7: resource_id 99 does not exist",
+				"sections.json: id 8: parent_id 77 does not exist",
+			},
+		},
+		{
+			name: "duplicate sibling positions",
+			file: "sections.json",
+			data: append(testutil.TestSections(),
+				models.Section{ID: 7, ResourceID: 1, Kind: "chapter", Title: "Chapter 3 bis", Position: 3},
+			),
+			expected: []string{
+				"sections.json: id 7: position 3 is already used by sibling section 3",
+			},
+		},
+		{
+			name: "parent cycle",
+			file: "sections.json",
+			data: append(testutil.TestSections(),
+				models.Section{ID: 7, ResourceID: 1, ParentID: intPtr(8), Kind: "sub-chapter", Title: "A", Position: 1},
+				models.Section{ID: 8, ResourceID: 1, ParentID: intPtr(7), Kind: "sub-chapter", Title: "B", Position: 1},
+			),
+			expected: []string{
+				"sections.json: id 7: parent_id cycle: 7 -> 8 -> 7",
+			},
+		},
+		{
+			name: "item in missing section and duplicate position",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 50, Kind: "technique", Title: "Nowhere", Position: 1, Attributes: attrs},
+				models.Item{ID: 8, SectionID: 2, Kind: "technique", Title: "Clash", Position: 2, Attributes: attrs},
+			),
+			expected: []string{
+				"items.json: id 7: section_id 50 does not exist",
+				"items.json: id 8: position 2 is already used by sibling item 5",
+			},
+		},
+		{
+			name: "attributes that do not match the kind's schema",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 3, Kind: "technique", Title: "Bare", Position: 1},
+				models.Item{ID: 8, SectionID: 3, Kind: "technique", Title: "Typo", Position: 2,
+					Attributes: json.RawMessage(`{"instructions":"Step 1","historical_image":"/assets/x.jpg"}`)},
+				models.Item{ID: 9, SectionID: 3, Kind: "technique", Title: "Wrong type", Position: 3,
+					Attributes: json.RawMessage(`{"instructions":"Step 1","historical_image_url":42}`)},
+				models.Item{ID: 10, SectionID: 3, Kind: "kata", Title: "Unknown kind", Position: 4, Attributes: attrs},
+			),
+			expected: []string{
+				"items.json: id 7: attributes: instructions is required",
+				`items.json: id 8: attributes: unknown field "historical_image"`,
+				"items.json: id 9: attributes: historical_image_url must be a string, not number",
+				`items.json: id 10: kind "kata" is not one of drill, plate, quote, technique, video`,
+			},
+		},
+		{
+			name: "clashing resource slugs",
+			file: "resources.json",
+			data: append(testutil.TestResources(),
+				models.Resource{ID: 6, Title: "Book A!", Description: "Same slug as Book A"},
+				models.Resource{ID: 7, Title: "Book G", Description: "Slug is a number", Slug: "1409"},
+			),
+			expected: []string{
+				`resources.json: id 6: slug "book-a" is already used by resource 1`,
+				`resources.json: id 7: slug "1409" must not be a number`,
+			},
+		},
+		{
+			name: "invalid item slugs",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 3, Kind: "technique", Title: "Technique 1", Position: 1, Attributes: attrs},
+				models.Item{ID: 8, SectionID: 3, Kind: "technique", Title: "Tangled", Slug: "Tangled Up", Position: 2, Attributes: attrs},
+				models.Item{ID: 9, SectionID: 3, Kind: "technique", Title: "Technique 9", Position: 3, Attributes: attrs,
+					FormerSlugs: []string{"technique-2", "old-name"}},
+				models.Item{ID: 10, SectionID: 3, Kind: "technique", Title: "Renamed", Position: 4, Attributes: attrs,
+					FormerSlugs: []string{"old-name"}},
+			),
+			expected: []string{
+				`items.json: id 7: slug "technique-1" is already used by item 1`,
+				`items.json: id 8: slug "Tangled Up" is not a valid slug (expected "tangled-up")`,
+				`items.json: id 9: former slug "technique-2" is already used by item 2`,
+				`items.json: id 10: former slug "old-name" is already used by item 9`,
+			},
+		},
+		{
+			name: "slugs reserved for routes",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 3, Kind: "technique", Title: "Graph", Position: 1, Attributes: attrs},
+				models.Item{ID: 8, SectionID: 3, Kind: "technique", Title: "Cross-references", Position: 2, Attributes: attrs,
+					FormerSlugs: []string{"concordance"}},
+				models.Item{ID: 9, SectionID: 3, Kind: "technique", Title: "Items", Position: 3, Attributes: attrs},
+			),
+			expected: []string{
+				`items.json: id 7: slug "graph" is reserved by the /api/items/{id}/graph route`,
+				`items.json: id 8: former slug "concordance" is reserved by the /api/items/{id}/concordance route`,
+			},
+		},
+		{
+			name: "invalid item relations",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 3, Kind: "technique", Title: "Tangled", Position: 1, Attributes: attrs,
+					Relations: []models.ItemRelation{
+						{Type: "parries", ItemID: 1},
+						{Type: models.ItemRelationFollowUp, ItemID: 7},
+						{Type: models.ItemRelationCounters, ItemID: 42},
+						{Type: models.ItemRelationIsRemedyOf, ItemID: 2},
+						{Type: models.ItemRelationIsRemedyOf, ItemID: 2},
+					}},
+			),
+			expected: []string{
+				`items.json: id 7: unknown relation type "parries"`,
+				"items.json: id 7: follow_up relation points at the item itself",
+				"items.json: id 7: counters relation item_id 42 does not exist",
+				"items.json: id 7: duplicate is_remedy_of relation to item 2",
+			},
+		},
+		{
+			name: "tags that are not taxonomy terms",
+			file: "items.json",
+			data: append(testutil.TestItems(),
+				models.Item{ID: 7, SectionID: 3, Kind: "technique", Title: "Axe", Position: 1, Attributes: attrs,
+					Tags: &models.Tags{Weapons: []string{"axe"}, Actions: []string{"cut", "parry"}, Armour: "padded"}},
+			),
+			expected: []string{
+				`items.json: id 7: unknown weapon tag "axe"`,
+				`items.json: id 7: unknown action tag "parry"`,
+				`items.json: id 7: unknown armour tag "padded"`,
+			},
+		},
+		{
+			name: "invalid concordance links",
+			file: "concordance.json",
+			data: append(testutil.TestConcordance(),
+				models.ConcordanceLink{ItemID: 2, RelatedItemID: 3, Type: "opposite"},
+				models.ConcordanceLink{ItemID: 42, RelatedItemID: 1, Type: models.ConcordanceSame},
+				models.ConcordanceLink{ItemID: 1, RelatedItemID: 43, Type: models.ConcordanceSame},
+				models.ConcordanceLink{ItemID: 5, RelatedItemID: 5, Type: models.ConcordanceVariant},
+				models.ConcordanceLink{ItemID: 4, RelatedItemID: 1, Type: models.ConcordanceSame},
+				models.ConcordanceLink{ItemID: 1, RelatedItemID: 3, Type: models.ConcordanceCounter},
+			),
+			expected: []string{
+				`concordance.json: link #5: unknown type "opposite"`,
+				"concordance.json: link #6: item_id 42 does not exist",
+				"concordance.json: link #7: related_item_id 43 does not exist",
+				"concordance.json: link #8: links item 5 to itself",
+				"concordance.json: link #9: duplicates link #1",
+			},
+		},
+		{
+			name: "duplicate taxonomy terms",
+			file: "taxonomy.json",
+			data: func() *taxonomy.Taxonomy {
+				tax := testutil.TestTaxonomy()
+				tax.Guards = append(tax.Guards, taxonomy.Term{Slug: "posta-di-donna", Name: "Woman's Guard"}, taxonomy.Term{Slug: "posta-longa"})
+				return tax
+			}(),
+			expected: []string{
+				`taxonomy.json: guard term "posta-di-donna" is defined twice`,
+				`taxonomy.json: guard term "posta-longa" has no name`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testutil.WriteDataDir(t)
+			testutil.WriteDataFile(t, dir, tt.file, tt.data)
+
+			_, err := store.Load(os.DirFS(dir))
+
+			var verr *store.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a ValidationError, got %v", err)
+			}
+
+			var got []string
+			for _, p := range verr.Problems {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("unexpected problems\nexpected:\n%s\ngot:\n%s",
+					strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_ImportFrom_ValidatesAttributes(t *testing.T) {
+	items := testutil.TestItems()
+	items[0].Attributes = json.RawMessage(`{"historical_image_url": "/assets/x.jpg"}`)
+	src := store.NewFromData(testutil.TestAuthors(), testutil.TestResources(), testutil.TestSections(), items, testutil.TestConcordance(), testutil.TestTaxonomy())
+
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "content.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	err = db.ImportFrom(src)
+	if err == nil || !strings.Contains(err.Error(), "item 1: attributes: instructions is required") {
+		t.Fatalf("expected import to reject item 1, got %v", err)
+	}
+
+	empty, err := db.IsEmpty()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !empty {
+		t.Error("expected the failed import to be rolled back")
+	}
+}
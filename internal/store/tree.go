@@ -0,0 +1,106 @@
+package store
+
+import (
+	"fmt"
+
+	"hema-lessons/internal/models"
+)
+
+// TreeNode is a section with its child sections and items, nested to any
+// depth.
+type TreeNode struct {
+	models.Section
+	Items    []models.Item `json:"items,omitempty"`
+	Children []TreeNode    `json:"children,omitempty"`
+}
+
+// ResourceTree is a resource's whole table of contents: its root sections,
+// their descendants and every section's items.
+type ResourceTree struct {
+	ResourceWithAuthor
+	Sections []TreeNode `json:"sections"`
+}
+
+// Prune returns a copy of the tree that keeps depth levels of sections (every
+// level when depth <= 0) and drops items unless withItems is set. The tree
+// itself is left untouched, so a precomputed tree can be shared.
+func (t *ResourceTree) Prune(depth int, withItems bool) *ResourceTree {
+	return &ResourceTree{
+		ResourceWithAuthor: t.ResourceWithAuthor,
+		Sections:           pruneNodes(t.Sections, depth, withItems),
+	}
+}
+
+func pruneNodes(nodes []TreeNode, depth int, withItems bool) []TreeNode {
+	pruned := make([]TreeNode, len(nodes))
+	for i, n := range nodes {
+		pruned[i] = TreeNode{Section: n.Section}
+		if withItems {
+			pruned[i].Items = n.Items
+		}
+		if depth != 1 && len(n.Children) > 0 {
+			pruned[i].Children = pruneNodes(n.Children, depth-1, withItems)
+		}
+	}
+	return pruned
+}
+
+// BuildResourceTree assembles the full tree of a resource from any
+// ContentRepository by walking ListRootSectionsByResourceID and
+// ListChildSections, or returns nil if the resource does not exist.
+func BuildResourceTree(repo ContentRepository, resourceID int) (*ResourceTree, error) {
+	resource, err := repo.GetResourceByID(resourceID)
+	if err != nil || resource == nil {
+		return nil, err
+	}
+
+	roots, err := repo.ListRootSectionsByResourceID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := buildTreeNodes(repo, roots, make(map[int]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceTree{ResourceWithAuthor: *resource, Sections: sections}, nil
+}
+
+func buildTreeNodes(repo ContentRepository, sections []models.Section, seen map[int]bool) ([]TreeNode, error) {
+	nodes := make([]TreeNode, 0, len(sections))
+	for _, sec := range sections {
+		if seen[sec.ID] {
+			return nil, fmt.Errorf("section %d: parent_id cycle", sec.ID)
+		}
+		seen[sec.ID] = true
+
+		items, err := repo.ListItemsBySectionID(sec.ID)
+		if err != nil {
+			return nil, err
+		}
+		children, err := repo.ListChildSections(sec.ID)
+		if err != nil {
+			return nil, err
+		}
+		childNodes, err := buildTreeNodes(repo, children, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, TreeNode{Section: sec, Items: items, Children: childNodes})
+	}
+	return nodes, nil
+}
+
+// buildResourceTrees builds the tree of every resource, keyed by resource ID.
+func buildResourceTrees(repo ContentRepository, resources []models.Resource) (map[int]*ResourceTree, error) {
+	trees := make(map[int]*ResourceTree, len(resources))
+	for _, r := range resources {
+		tree, err := BuildResourceTree(repo, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("resource %d: %w", r.ID, err)
+		}
+		trees[r.ID] = tree
+	}
+	return trees, nil
+}
@@ -0,0 +1,405 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"hema-lessons/internal/itemkind"
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/textnorm"
+)
+
+// Problem is a single integrity violation found in the data files.
+type Problem struct {
+	File    string `json:"file"`
+	ID      int    `json:"id"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.ID == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s: id %d: %s", p.File, p.ID, p.Message)
+}
+
+// ValidationError reports every Problem found in a dataset.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "content failed validation with %d problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// validate checks the dataset for referential integrity and returns a
+// *ValidationError listing every problem, or nil if there are none.
+func (d *dataset) validate() error {
+	v := &validator{}
+
+	authorIDs := v.uniqueIDs(authorsFile, len(d.authors), func(i int) int { return d.authors[i].ID })
+	resourceIDs := v.uniqueIDs(resourcesFile, len(d.resources), func(i int) int { return d.resources[i].ID })
+	sectionIDs := v.uniqueIDs(sectionsFile, len(d.sections), func(i int) int { return d.sections[i].ID })
+	itemIDs := v.uniqueIDs(itemsFile, len(d.items), func(i int) int { return d.items[i].ID })
+
+	tax := d.taxonomy
+	if tax == nil {
+		tax = &taxonomy.Taxonomy{}
+	}
+	for _, msg := range tax.Check() {
+		v.add(taxonomyFile, 0, "%s", msg)
+	}
+
+	for _, a := range d.authors {
+		type relationKey struct {
+			relType  string
+			authorID int
+		}
+		seen := make(map[relationKey]bool, len(a.Relations))
+		for _, rel := range a.Relations {
+			if !models.IsValidAuthorRelation(rel.Type) {
+				v.add(authorsFile, a.ID, "unknown relation type %q", rel.Type)
+			}
+			switch {
+			case rel.AuthorID == a.ID:
+				v.add(authorsFile, a.ID, "%s relation points at the author itself", rel.Type)
+			case !authorIDs[rel.AuthorID]:
+				v.add(authorsFile, a.ID, "%s relation author_id %d does not exist", rel.Type, rel.AuthorID)
+			}
+			key := relationKey{rel.Type, rel.AuthorID}
+			if seen[key] {
+				v.add(authorsFile, a.ID, "duplicate %s relation to author %d", rel.Type, rel.AuthorID)
+			}
+			seen[key] = true
+		}
+	}
+
+	for _, r := range d.resources {
+		if r.AuthorID != nil && !authorIDs[*r.AuthorID] {
+			v.add(resourcesFile, r.ID, "author_id %d does not exist", *r.AuthorID)
+		}
+	}
+
+	sections := make(map[int]models.Section, len(d.sections))
+	for _, sec := range d.sections {
+		sections[sec.ID] = sec
+	}
+
+	type sectionSlot struct {
+		resourceID, parentID, position int
+	}
+	sectionPositions := make(map[sectionSlot]int)
+	for _, sec := range d.sections {
+		if !resourceIDs[sec.ResourceID] {
+			v.add(sectionsFile, sec.ID, "resource_id %d does not exist", sec.ResourceID)
+		}
+
+		parentID := 0
+		if sec.ParentID != nil {
+			parentID = *sec.ParentID
+			parent, ok := sections[parentID]
+			switch {
+			case !ok:
+				v.add(sectionsFile, sec.ID, "parent_id %d does not exist", parentID)
+			case parent.ResourceID != sec.ResourceID:
+				v.add(sectionsFile, sec.ID, "parent_id %d belongs to resource %d, not %d", parentID, parent.ResourceID, sec.ResourceID)
+			}
+		}
+
+		for _, msg := range tax.CheckTags(sec.Tags) {
+			v.add(sectionsFile, sec.ID, "%s", msg)
+		}
+
+		slot := sectionSlot{sec.ResourceID, parentID, sec.Position}
+		if other, dup := sectionPositions[slot]; dup && other != sec.ID {
+			v.add(sectionsFile, sec.ID, "position %d is already used by sibling section %d", sec.Position, other)
+		} else {
+			sectionPositions[slot] = sec.ID
+		}
+	}
+
+	v.sectionCycles(sections)
+
+	type itemSlot struct {
+		sectionID, position int
+	}
+	itemPositions := make(map[itemSlot]int)
+	for _, item := range d.items {
+		if !sectionIDs[item.SectionID] {
+			v.add(itemsFile, item.ID, "section_id %d does not exist", item.SectionID)
+		}
+		if err := itemkind.Validate(item.Kind, item.Attributes); err != nil {
+			v.add(itemsFile, item.ID, "%v", err)
+		}
+		for _, msg := range tax.CheckTags(item.Tags) {
+			v.add(itemsFile, item.ID, "%s", msg)
+		}
+
+		type relationKey struct {
+			relType string
+			itemID  int
+		}
+		seen := make(map[relationKey]bool, len(item.Relations))
+		for _, rel := range item.Relations {
+			if !models.IsValidItemRelation(rel.Type) {
+				v.add(itemsFile, item.ID, "unknown relation type %q", rel.Type)
+			}
+			switch {
+			case rel.ItemID == item.ID:
+				v.add(itemsFile, item.ID, "%s relation points at the item itself", rel.Type)
+			case !itemIDs[rel.ItemID]:
+				v.add(itemsFile, item.ID, "%s relation item_id %d does not exist", rel.Type, rel.ItemID)
+			}
+			key := relationKey{rel.Type, rel.ItemID}
+			if seen[key] {
+				v.add(itemsFile, item.ID, "duplicate %s relation to item %d", rel.Type, rel.ItemID)
+			}
+			seen[key] = true
+		}
+
+		slot := itemSlot{item.SectionID, item.Position}
+		if other, dup := itemPositions[slot]; dup && other != item.ID {
+			v.add(itemsFile, item.ID, "position %d is already used by sibling item %d", item.Position, other)
+		} else {
+			itemPositions[slot] = item.ID
+		}
+	}
+
+	v.slugs(d.resources, d.sections, d.items)
+	v.concordance(d.concordance, d.items)
+
+	return v.err()
+}
+
+// reservedSectionSlugs and reservedItemSlugs are the literal segments that
+// follow an ID in the API's routes, such as /api/sections/{id}/graph. The
+// router prefers those routes over the permalinks /api/sections/{resource}/{slug}
+// and /api/items/{resource}/{slug}, so a record with one of these slugs could
+// never be reached by it.
+var (
+	reservedSectionSlugs = []string{"sections", "items", "graph"}
+	reservedItemSlugs    = []string{"concordance", "graph"}
+)
+
+// slugs reports malformed, numeric and reserved slugs, and slugs used twice
+// within a scope: across resources, or among the sections or the items of
+// one resource. Current slugs are claimed before former ones, so a clash is
+// reported against the former slug.
+func (v *validator) slugs(resources []models.Resource, sections []models.Section, items []models.Item) {
+	resourceBySection := make(map[int]int, len(sections))
+	for _, sec := range sections {
+		resourceBySection[sec.ID] = sec.ResourceID
+	}
+
+	resourceSlugs := make(map[string]int)
+	sectionSlugs := make(map[int]map[string]int)
+	itemSlugs := make(map[int]map[string]int)
+	scope := func(m map[int]map[string]int, resourceID int) map[string]int {
+		if m[resourceID] == nil {
+			m[resourceID] = make(map[string]int)
+		}
+		return m[resourceID]
+	}
+
+	for _, former := range []bool{false, true} {
+		for _, r := range resources {
+			v.slugSet(resourcesFile, "resource", nil, resourceSlugs, r.ID, resourceSlug(r), r.FormerSlugs, former)
+		}
+		for _, sec := range sections {
+			v.slugSet(sectionsFile, "section", reservedSectionSlugs, scope(sectionSlugs, sec.ResourceID), sec.ID, sectionSlug(sec), sec.FormerSlugs, former)
+		}
+		for _, item := range items {
+			v.slugSet(itemsFile, "item", reservedItemSlugs, scope(itemSlugs, resourceBySection[item.SectionID]), item.ID, itemSlug(item), item.FormerSlugs, former)
+		}
+	}
+}
+
+// slugSet checks either the current slug or the former slugs of one record
+// and claims them in taken. Slugs in reserved are refused.
+func (v *validator) slugSet(file, kind string, reserved []string, taken map[string]int, id int, slug string, formerSlugs []string, former bool) {
+	field, slugs := "slug", []string{slug}
+	if former {
+		field, slugs = "former slug", formerSlugs
+	}
+	for _, s := range slugs {
+		switch {
+		case s == "":
+			v.add(file, id, "%s is empty", field)
+			continue
+		case textnorm.Slugify(s) != s:
+			v.add(file, id, "%s %q is not a valid slug (expected %q)", field, s, textnorm.Slugify(s))
+		case isNumber(s):
+			v.add(file, id, "%s %q must not be a number", field, s)
+		case slices.Contains(reserved, s):
+			v.add(file, id, "%s %q is reserved by the /api/%ss/{id}/%s route", field, s, kind, s)
+		}
+		if other, dup := taken[s]; dup && other != id {
+			v.add(file, id, "%s %q is already used by %s %d", field, s, kind, other)
+		} else {
+			taken[s] = id
+		}
+	}
+}
+
+// isNumber reports whether s is all digits, which would make a slug
+// indistinguishable from an ID in a URL.
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// concordance checks that every link joins two distinct, existing items and
+// that no link is given twice. Symmetric links count as duplicates in either
+// direction.
+func (v *validator) concordance(links []models.ConcordanceLink, items []models.Item) {
+	itemIDs := make(map[int]bool, len(items))
+	for _, item := range items {
+		itemIDs[item.ID] = true
+	}
+
+	type linkKey struct {
+		from, to int
+		linkType string
+	}
+	seen := make(map[linkKey]int, len(links))
+	for i, l := range links {
+		n := i + 1
+		if !models.IsValidConcordanceType(l.Type) {
+			v.add(concordanceFile, 0, "link #%d: unknown type %q", n, l.Type)
+		}
+		switch {
+		case !itemIDs[l.ItemID]:
+			v.add(concordanceFile, 0, "link #%d: item_id %d does not exist", n, l.ItemID)
+		case !itemIDs[l.RelatedItemID]:
+			v.add(concordanceFile, 0, "link #%d: related_item_id %d does not exist", n, l.RelatedItemID)
+		case l.ItemID == l.RelatedItemID:
+			v.add(concordanceFile, 0, "link #%d: links item %d to itself", n, l.ItemID)
+		}
+
+		key := linkKey{l.ItemID, l.RelatedItemID, l.Type}
+		if models.IsSymmetricConcordanceType(l.Type) && key.from > key.to {
+			key.from, key.to = key.to, key.from
+		}
+		if other, dup := seen[key]; dup {
+			v.add(concordanceFile, 0, "link #%d: duplicates link #%d", n, other)
+		} else {
+			seen[key] = n
+		}
+	}
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(file string, id int, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{File: file, ID: id, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// uniqueIDs reports non-positive and duplicate IDs and returns the set of IDs seen.
+func (v *validator) uniqueIDs(file string, n int, idAt func(i int) int) map[int]bool {
+	seen := make(map[int]bool, n)
+	for i := 0; i < n; i++ {
+		id := idAt(i)
+		if id <= 0 {
+			v.add(file, id, "id must be positive (entry #%d)", i+1)
+		}
+		if seen[id] {
+			v.add(file, id, "duplicate id (entry #%d)", i+1)
+		}
+		seen[id] = true
+	}
+	return seen
+}
+
+// sectionCycles reports every parent_id cycle once, against its lowest section ID.
+func (v *validator) sectionCycles(sections map[int]models.Section) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[int]int, len(sections))
+
+	ids := make([]int, 0, len(sections))
+	for id := range sections {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, start := range ids {
+		var path []int
+		id := start
+		for {
+			if state[id] == done {
+				break
+			}
+			if state[id] == visiting {
+				cycle := path[indexOf(path, id):]
+				lowest := cycle[0]
+				for _, c := range cycle {
+					if c < lowest {
+						lowest = c
+					}
+				}
+				v.add(sectionsFile, lowest, "parent_id cycle: %s", formatCycle(cycle, lowest))
+				break
+			}
+
+			state[id] = visiting
+			path = append(path, id)
+
+			sec, ok := sections[id]
+			if !ok || sec.ParentID == nil {
+				break
+			}
+			if _, ok := sections[*sec.ParentID]; !ok {
+				break
+			}
+			id = *sec.ParentID
+		}
+		for _, p := range path {
+			state[p] = done
+		}
+	}
+}
+
+func indexOf(ids []int, id int) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatCycle renders a cycle as "3 -> 5 -> 3", starting from the given ID.
+func formatCycle(cycle []int, from int) string {
+	start := indexOf(cycle, from)
+	parts := make([]string, 0, len(cycle)+1)
+	for i := range cycle {
+		parts = append(parts, fmt.Sprint(cycle[(start+i)%len(cycle)]))
+	}
+	parts = append(parts, fmt.Sprint(from))
+	return strings.Join(parts, " -> ")
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Version identifies the content a repository is serving. Hash is a SHA-256
+// over every record, so it changes exactly when the content does; LoadedAt is
+// when content with that hash was first loaded.
+type Version struct {
+	Hash     string
+	LoadedAt time.Time
+}
+
+// newVersion hashes the dataset. If prev has the same hash the content has
+// not changed since it was loaded, so its LoadedAt is kept.
+func newVersion(d *dataset, prev *Version) Version {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, v := range []any{d.authors, d.resources, d.sections, d.items, d.taxonomy, d.concordance} {
+		// Writes to a hash cannot fail, and every record type encodes.
+		_ = enc.Encode(v)
+	}
+
+	v := Version{Hash: hex.EncodeToString(h.Sum(nil)), LoadedAt: time.Now().UTC()}
+	if prev != nil && prev.Hash == v.Hash {
+		v.LoadedAt = prev.LoadedAt
+	}
+	return v
+}
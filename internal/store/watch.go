@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the burst of events an editor or `git checkout`
+// produces into a single reload.
+const reloadDebounce = 250 * time.Millisecond
+
+// Watch reloads the Store whenever a JSON file in dir changes, until ctx is
+// cancelled. The Store should have been loaded with Load(os.DirFS(dir)).
+// A reload that fails is logged and the last good snapshot keeps serving.
+func (s *Store) Watch(ctx context.Context, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(reloadDebounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(event.Name) != ".json" || event.Op == fsnotify.Chmod {
+					continue
+				}
+				timer.Reset(reloadDebounce)
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Error("data directory watcher error", "dir", dir, "error", err)
+
+			case <-timer.C:
+				if err := s.Reload(); err != nil {
+					slog.Error("rejected data reload, keeping previous snapshot", "dir", dir, "error", err)
+					continue
+				}
+				slog.Info("data reloaded", "dir", dir)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,196 @@
+// Package suggest offers type-ahead completion of titles using a prefix trie.
+//
+// Bilingual titles such as "Posta di Finestra (Window Guard)" are indexed
+// under each half, so both "posta di f" and "window" find them.
+package suggest
+
+import (
+	"sort"
+	"strings"
+
+	"hema-lessons/internal/textnorm"
+)
+
+// Entry is a title that can be suggested.
+type Entry struct {
+	Type  string `json:"type"`
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type node struct {
+	children map[rune]*node
+	entries  []keyed // entries whose key ends at this node
+}
+
+type keyed struct {
+	entry int // index into Trie.entries
+	key   string
+}
+
+// Trie is an immutable prefix trie over the keys of a set of titles.
+type Trie struct {
+	root      *node
+	entries   []Entry
+	typeOrder map[string]int
+}
+
+// New builds a Trie from entries. typeOrder lists entry types in the order
+// they should appear when suggestions otherwise tie; unlisted types sort last.
+func New(entries []Entry, typeOrder ...string) *Trie {
+	t := &Trie{
+		root:      &node{},
+		entries:   entries,
+		typeOrder: make(map[string]int, len(typeOrder)),
+	}
+	for i, typ := range typeOrder {
+		t.typeOrder[typ] = i
+	}
+
+	for i, e := range entries {
+		for _, key := range Keys(e.Title) {
+			t.insert(key, i)
+		}
+	}
+	return t
+}
+
+func (t *Trie) insert(key string, entry int) {
+	n := t.root
+	for _, r := range key {
+		if n.children == nil {
+			n.children = make(map[rune]*node)
+		}
+		child, ok := n.children[r]
+		if !ok {
+			child = &node{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	n.entries = append(n.entries, keyed{entry: entry, key: key})
+}
+
+// Suggest returns up to limit entries with a key starting with prefix.
+// Exact matches come first, then shorter keys, then entries by type order,
+// title and ID. Each entry is returned at most once.
+func (t *Trie) Suggest(prefix string, limit int) []Entry {
+	p := normalize(prefix)
+	if p == "" || limit <= 0 {
+		return nil
+	}
+
+	n := t.root
+	for _, r := range p {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+
+	// Keep the best (shortest) key per entry.
+	best := make(map[int]string)
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, k := range n.entries {
+			if cur, ok := best[k.entry]; !ok || len(k.key) < len(cur) {
+				best[k.entry] = k.key
+			}
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	matches := make([]int, 0, len(best))
+	for i := range best {
+		matches = append(matches, i)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		ka, kb := best[a], best[b]
+		if (ka == p) != (kb == p) {
+			return ka == p
+		}
+		if len(ka) != len(kb) {
+			return len(ka) < len(kb)
+		}
+		ea, eb := t.entries[a], t.entries[b]
+		if ra, rb := t.rank(ea.Type), t.rank(eb.Type); ra != rb {
+			return ra < rb
+		}
+		if ea.Title != eb.Title {
+			return ea.Title < eb.Title
+		}
+		return ea.ID < eb.ID
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	out := make([]Entry, len(matches))
+	for i, m := range matches {
+		out[i] = t.entries[m]
+	}
+	return out
+}
+
+func (t *Trie) rank(typ string) int {
+	if r, ok := t.typeOrder[typ]; ok {
+		return r
+	}
+	return len(t.typeOrder)
+}
+
+// Keys returns the normalised keys a title is indexed under: the text outside
+// parentheses and the text inside each pair of parentheses.
+func Keys(title string) []string {
+	var (
+		keys    []string
+		outside strings.Builder
+		inside  strings.Builder
+		depth   int
+	)
+	for _, r := range title {
+		switch {
+		case r == '(':
+			if depth == 0 {
+				inside.Reset()
+			}
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+			if depth == 0 {
+				keys = appendKey(keys, inside.String())
+			}
+		case depth > 0:
+			inside.WriteRune(r)
+		default:
+			outside.WriteRune(r)
+		}
+	}
+	if depth > 0 {
+		keys = appendKey(keys, inside.String())
+	}
+	return appendKey(keys, outside.String())
+}
+
+func appendKey(keys []string, text string) []string {
+	key := normalize(text)
+	if key == "" {
+		return keys
+	}
+	for _, k := range keys {
+		if k == key {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
+
+// normalize folds text into words separated by single spaces, dropping
+// apostrophes, so "Peasant's Strike" becomes "peasants strike".
+func normalize(text string) string {
+	return strings.ReplaceAll(textnorm.Slugify(text), "-", " ")
+}
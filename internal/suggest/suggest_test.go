@@ -0,0 +1,108 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testTrie() *Trie {
+	return New([]Entry{
+		{Type: "item", ID: 1, Title: "Posta di Finestra (Window Guard)"},
+		{Type: "item", ID: 2, Title: "Posta di Donna (Woman's Guard)"},
+		{Type: "item", ID: 3, Title: "Posta di Finestra (Window Guard)"},
+		{Type: "item", ID: 4, Title: "Colpo di Villano (Peasant's Strike)"},
+		{Type: "section", ID: 1, Title: "Sword in Armor (Spada in arme)"},
+		{Type: "item", ID: 5, Title: "Posta"},
+		{Type: "item", ID: 6, Title: "Zwerchhau"},
+	}, "section", "item")
+}
+
+func TestTrie_Suggest(t *testing.T) {
+	trie := testTrie()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		limit    int
+		expected []int
+	}{
+		{
+			name:     "vernacular half",
+			prefix:   "posta di f",
+			limit:    10,
+			expected: []int{1, 3},
+		},
+		{
+			name:     "english half",
+			prefix:   "Window",
+			limit:    10,
+			expected: []int{1, 3},
+		},
+		{
+			name:     "exact match first, then shorter keys",
+			prefix:   "posta",
+			limit:    10,
+			expected: []int{5, 2, 1, 3},
+		},
+		{
+			name:     "limit",
+			prefix:   "posta",
+			limit:    2,
+			expected: []int{5, 2},
+		},
+		{
+			name:     "apostrophes and case are ignored",
+			prefix:   "PEASANTS str",
+			limit:    10,
+			expected: []int{4},
+		},
+		{
+			name:     "parenthesised vernacular of a section",
+			prefix:   "spada",
+			limit:    10,
+			expected: []int{1},
+		},
+		{
+			name:     "middle of a half does not match",
+			prefix:   "finestra",
+			limit:    10,
+			expected: nil,
+		},
+		{
+			name:     "blank prefix",
+			prefix:   "  ",
+			limit:    10,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, e := range trie.Suggest(tt.prefix, tt.limit) {
+				got = append(got, e.ID)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected []string
+	}{
+		{"Posta di Finestra (Window Guard)", []string{"window guard", "posta di finestra"}},
+		{"Colpo di Villano (Peasant's Strike - One Hand)", []string{"peasants strike one hand", "colpo di villano"}},
+		{"Zwerchhau", []string{"zwerchhau"}},
+		{"Stück (Stuck)", []string{"stuck"}},
+	}
+
+	for _, tt := range tests {
+		if got := Keys(tt.title); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Keys(%q): expected %v, got %v", tt.title, tt.expected, got)
+		}
+	}
+}
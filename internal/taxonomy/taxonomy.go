@@ -0,0 +1,309 @@
+// Package taxonomy defines the controlled vocabulary used to tag items and
+// sections (weapons, guards, actions and armour) and filters content by it.
+package taxonomy
+
+import (
+	"fmt"
+	"sort"
+
+	"hema-lessons/internal/models"
+)
+
+// Facet names, used as query parameters and in validation messages.
+const (
+	FacetWeapon = "weapon"
+	FacetGuard  = "guard"
+	FacetAction = "action"
+	FacetArmour = "armour"
+)
+
+// Facets lists every facet in display order.
+var Facets = []string{FacetWeapon, FacetGuard, FacetAction, FacetArmour}
+
+// Term is one value of a facet.
+type Term struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// Taxonomy is the set of terms content may be tagged with, as read from
+// taxonomy.json.
+type Taxonomy struct {
+	Weapons []Term `json:"weapons"`
+	Guards  []Term `json:"guards"`
+	Actions []Term `json:"actions"`
+	Armour  []Term `json:"armour"`
+}
+
+// Terms returns the terms of a facet, or nil for an unknown facet.
+func (t *Taxonomy) Terms(facet string) []Term {
+	switch facet {
+	case FacetWeapon:
+		return t.Weapons
+	case FacetGuard:
+		return t.Guards
+	case FacetAction:
+		return t.Actions
+	case FacetArmour:
+		return t.Armour
+	}
+	return nil
+}
+
+// Check returns a message for every term of the taxonomy that is defined
+// twice or has an empty slug or name.
+func (t *Taxonomy) Check() []string {
+	var problems []string
+	for _, facet := range Facets {
+		seen := make(map[string]bool)
+		for i, term := range t.Terms(facet) {
+			switch {
+			case term.Slug == "":
+				problems = append(problems, fmt.Sprintf("%s term #%d has no slug", facet, i+1))
+			case seen[term.Slug]:
+				problems = append(problems, fmt.Sprintf("%s term %q is defined twice", facet, term.Slug))
+			}
+			if term.Name == "" {
+				problems = append(problems, fmt.Sprintf("%s term %q has no name", facet, term.Slug))
+			}
+			seen[term.Slug] = true
+		}
+	}
+	return problems
+}
+
+// CheckTags returns a message for every tag that is not a term of the taxonomy.
+func (t *Taxonomy) CheckTags(tags *models.Tags) []string {
+	if tags == nil {
+		return nil
+	}
+	var problems []string
+	for _, facet := range Facets {
+		for _, slug := range values(tags, facet) {
+			if !t.has(facet, slug) {
+				problems = append(problems, fmt.Sprintf("unknown %s tag %q", facet, slug))
+			}
+		}
+	}
+	return problems
+}
+
+func (t *Taxonomy) has(facet, slug string) bool {
+	for _, term := range t.Terms(facet) {
+		if term.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the slugs a Tags value holds for a facet.
+func values(tags *models.Tags, facet string) []string {
+	if tags == nil {
+		return nil
+	}
+	switch facet {
+	case FacetWeapon:
+		return tags.Weapons
+	case FacetGuard:
+		return tags.Guards
+	case FacetAction:
+		return tags.Actions
+	case FacetArmour:
+		if tags.Armour != "" {
+			return []string{tags.Armour}
+		}
+	}
+	return nil
+}
+
+// Merge returns the tags a child inherits from its parent: the union of both
+// for weapons, guards and actions, and the child's armour if set.
+func Merge(parent, child *models.Tags) models.Tags {
+	var merged models.Tags
+	if parent != nil {
+		merged = models.Tags{
+			Weapons: append([]string(nil), parent.Weapons...),
+			Guards:  append([]string(nil), parent.Guards...),
+			Actions: append([]string(nil), parent.Actions...),
+			Armour:  parent.Armour,
+		}
+	}
+	if child == nil {
+		return merged
+	}
+	merged.Weapons = union(merged.Weapons, child.Weapons)
+	merged.Guards = union(merged.Guards, child.Guards)
+	merged.Actions = union(merged.Actions, child.Actions)
+	if child.Armour != "" {
+		merged.Armour = child.Armour
+	}
+	return merged
+}
+
+func union(a, b []string) []string {
+	for _, v := range b {
+		found := false
+		for _, w := range a {
+			if v == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, v)
+		}
+	}
+	return a
+}
+
+// Filter selects content by tags. Within a facet any listed term matches;
+// across facets every non-empty facet must match.
+type Filter map[string][]string
+
+// IsEmpty reports whether the filter selects everything.
+func (f Filter) IsEmpty() bool {
+	for _, slugs := range f {
+		if len(slugs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether tags satisfy the filter.
+func (f Filter) Matches(tags models.Tags) bool {
+	for facet, want := range f {
+		if len(want) == 0 {
+			continue
+		}
+		have := values(&tags, facet)
+		matched := false
+		for _, w := range want {
+			for _, h := range have {
+				if w == h {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// TermCount is a term with the number of items tagged with it.
+type TermCount struct {
+	Term
+	Count int `json:"count"`
+}
+
+// Summary is the taxonomy with item counts, in taxonomy order.
+type Summary struct {
+	Weapons []TermCount `json:"weapons"`
+	Guards  []TermCount `json:"guards"`
+	Actions []TermCount `json:"actions"`
+	Armour  []TermCount `json:"armour"`
+}
+
+// Index holds the effective tags of every item: its own tags merged with
+// those of its section and the section's ancestors.
+type Index struct {
+	taxonomy *Taxonomy
+	ids      []int // item IDs in ascending order
+	tags     map[int]models.Tags
+}
+
+// NewIndex computes effective tags for items. Sections are looked up by ID to
+// walk parent links; a parent cycle stops the walk.
+func NewIndex(tax *Taxonomy, sections []models.Section, items []models.Item) *Index {
+	if tax == nil {
+		tax = &Taxonomy{}
+	}
+	byID := make(map[int]models.Section, len(sections))
+	for _, sec := range sections {
+		byID[sec.ID] = sec
+	}
+
+	sectionTags := make(map[int]models.Tags, len(sections))
+	var resolve func(id int, seen map[int]bool) models.Tags
+	resolve = func(id int, seen map[int]bool) models.Tags {
+		if tags, ok := sectionTags[id]; ok {
+			return tags
+		}
+		sec, ok := byID[id]
+		if !ok || seen[id] {
+			return models.Tags{}
+		}
+		seen[id] = true
+		var parent models.Tags
+		if sec.ParentID != nil {
+			parent = resolve(*sec.ParentID, seen)
+		}
+		tags := Merge(&parent, sec.Tags)
+		sectionTags[id] = tags
+		return tags
+	}
+
+	ix := &Index{taxonomy: tax, tags: make(map[int]models.Tags, len(items))}
+	for _, item := range items {
+		parent := resolve(item.SectionID, make(map[int]bool))
+		ix.tags[item.ID] = Merge(&parent, item.Tags)
+		ix.ids = append(ix.ids, item.ID)
+	}
+	sort.Ints(ix.ids)
+	return ix
+}
+
+// Taxonomy returns the taxonomy the index was built with.
+func (ix *Index) Taxonomy() *Taxonomy {
+	return ix.taxonomy
+}
+
+// Tags returns the effective tags of an item.
+func (ix *Index) Tags(itemID int) models.Tags {
+	return ix.tags[itemID]
+}
+
+// Match returns the IDs of items matching f, in ascending order.
+func (ix *Index) Match(f Filter) []int {
+	var ids []int
+	for _, id := range ix.ids {
+		if f.Matches(ix.tags[id]) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Summary counts the items tagged with each term.
+func (ix *Index) Summary() *Summary {
+	counts := make(map[string]map[string]int, len(Facets))
+	for _, facet := range Facets {
+		counts[facet] = make(map[string]int)
+	}
+	for _, tags := range ix.tags {
+		tags := tags
+		for _, facet := range Facets {
+			for _, slug := range values(&tags, facet) {
+				counts[facet][slug]++
+			}
+		}
+	}
+
+	count := func(facet string) []TermCount {
+		terms := ix.taxonomy.Terms(facet)
+		out := make([]TermCount, len(terms))
+		for i, term := range terms {
+			out[i] = TermCount{Term: term, Count: counts[facet][term.Slug]}
+		}
+		return out
+	}
+	return &Summary{
+		Weapons: count(FacetWeapon),
+		Guards:  count(FacetGuard),
+		Actions: count(FacetAction),
+		Armour:  count(FacetArmour),
+	}
+}
@@ -0,0 +1,107 @@
+package taxonomy
+
+import (
+	"reflect"
+	"testing"
+
+	"hema-lessons/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestMerge(t *testing.T) {
+	parent := &models.Tags{Weapons: []string{"dagger"}, Actions: []string{"lock"}, Armour: "unarmoured"}
+	child := &models.Tags{Actions: []string{"lock", "throw"}, Armour: "armoured"}
+
+	got := Merge(parent, child)
+	want := models.Tags{Weapons: []string{"dagger"}, Actions: []string{"lock", "throw"}, Armour: "armoured"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if len(parent.Actions) != 1 {
+		t.Errorf("expected Merge to leave the parent unchanged, got %+v", parent)
+	}
+
+	if got := Merge(nil, nil); !reflect.DeepEqual(got, models.Tags{}) {
+		t.Errorf("expected empty tags, got %+v", got)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	tags := models.Tags{Weapons: []string{"longsword"}, Guards: []string{"posta-di-donna"}, Actions: []string{"cut"}, Armour: "unarmoured"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"single facet", Filter{FacetWeapon: {"longsword"}}, true},
+		{"any term within a facet", Filter{FacetAction: {"thrust", "cut"}}, true},
+		{"every facet must match", Filter{FacetWeapon: {"longsword"}, FacetArmour: {"armoured"}}, false},
+		{"missing facet", Filter{FacetGuard: {"posta-longa"}}, false},
+		{"empty facet is ignored", Filter{FacetGuard: nil, FacetArmour: {"unarmoured"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tags); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaxonomy_CheckTags(t *testing.T) {
+	tax := &Taxonomy{
+		Weapons: []Term{{Slug: "dagger", Name: "Dagger"}},
+		Armour:  []Term{{Slug: "armoured", Name: "Armoured"}},
+	}
+
+	got := tax.CheckTags(&models.Tags{Weapons: []string{"dagger", "axe"}, Guards: []string{"posta-longa"}, Armour: "armoured"})
+	want := []string{`unknown weapon tag "axe"`, `unknown guard tag "posta-longa"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := tax.CheckTags(nil); got != nil {
+		t.Errorf("expected no problems for untagged content, got %q", got)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	tax := &Taxonomy{
+		Weapons: []Term{{Slug: "dagger", Name: "Dagger"}, {Slug: "spear", Name: "Spear"}},
+		Actions: []Term{{Slug: "lock", Name: "Lock"}, {Slug: "throw", Name: "Throw"}},
+	}
+	sections := []models.Section{
+		{ID: 1, Tags: &models.Tags{Weapons: []string{"dagger"}}},
+		{ID: 2, ParentID: intPtr(1)},
+		{ID: 3, ParentID: intPtr(4)},
+		{ID: 4, ParentID: intPtr(3)}, // parent cycle
+	}
+	items := []models.Item{
+		{ID: 3, SectionID: 2, Tags: &models.Tags{Actions: []string{"throw"}}},
+		{ID: 1, SectionID: 1, Tags: &models.Tags{Actions: []string{"lock"}}},
+		{ID: 2, SectionID: 3, Tags: &models.Tags{Actions: []string{"lock"}}},
+	}
+	ix := NewIndex(tax, sections, items)
+
+	if got := ix.Tags(3); !reflect.DeepEqual(got.Weapons, []string{"dagger"}) {
+		t.Errorf("expected item 3 to inherit dagger through section 2, got %+v", got)
+	}
+	if got := ix.Match(Filter{FacetAction: {"lock"}}); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("expected [1 2], got %v", got)
+	}
+	if got := ix.Match(Filter{FacetWeapon: {"dagger"}}); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("expected [1 3], got %v", got)
+	}
+
+	summary := ix.Summary()
+	want := []TermCount{{Term{"dagger", "Dagger"}, 2}, {Term{"spear", "Spear"}, 0}}
+	if !reflect.DeepEqual(summary.Weapons, want) {
+		t.Errorf("expected %+v, got %+v", want, summary.Weapons)
+	}
+	if summary.Guards == nil || len(summary.Guards) != 0 {
+		t.Errorf("expected an empty guards list, got %#v", summary.Guards)
+	}
+}
@@ -0,0 +1,164 @@
+// Package techgraph turns the relations between items into directed graphs
+// of techniques: which play counters which, what follows on from what, and
+// which remedy answers which attack. Graphs can be rendered as Graphviz DOT
+// to print decision trees.
+package techgraph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hema-lessons/internal/models"
+)
+
+// Node is an item in a technique graph.
+type Node struct {
+	ID        int    `json:"id"`
+	SectionID int    `json:"section_id"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+}
+
+// Edge is a typed relation from one item to another.
+type Edge struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Type string `json:"type"`
+	Note string `json:"note,omitempty"`
+}
+
+// Graph is a set of items and the relations between them. Nodes are ordered
+// by ID and edges by source ID, then in the order they were recorded.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Index holds every item and relation so that subgraphs can be cut from it.
+type Index struct {
+	nodes    map[int]Node
+	edges    []Edge
+	adjacent map[int][]int // node ID -> IDs of nodes sharing an edge with it
+}
+
+// New builds an Index from items and their relations. Relations pointing at
+// items that are not in the slice are ignored.
+func New(items []models.Item) *Index {
+	ix := &Index{
+		nodes:    make(map[int]Node, len(items)),
+		adjacent: make(map[int][]int),
+	}
+	for _, item := range items {
+		ix.nodes[item.ID] = Node{ID: item.ID, SectionID: item.SectionID, Kind: item.Kind, Title: item.Title}
+	}
+
+	sorted := make([]models.Item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, item := range sorted {
+		for _, rel := range item.Relations {
+			if _, ok := ix.nodes[rel.ItemID]; !ok {
+				continue
+			}
+			ix.edges = append(ix.edges, Edge{From: item.ID, To: rel.ItemID, Type: rel.Type, Note: rel.Note})
+			ix.adjacent[item.ID] = append(ix.adjacent[item.ID], rel.ItemID)
+			ix.adjacent[rel.ItemID] = append(ix.adjacent[rel.ItemID], item.ID)
+		}
+	}
+	return ix
+}
+
+// Has reports whether id is an item of the index.
+func (ix *Index) Has(id int) bool {
+	_, ok := ix.nodes[id]
+	return ok
+}
+
+// Neighbourhood returns the items within depth relations of id, following
+// relations in either direction, and every relation between them. Depth 0
+// yields the item alone. It returns an empty graph if id is unknown.
+func (ix *Index) Neighbourhood(id, depth int) Graph {
+	if !ix.Has(id) {
+		return ix.subgraph(nil)
+	}
+
+	members := map[int]bool{id: true}
+	frontier := []int{id}
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []int
+		for _, n := range frontier {
+			for _, m := range ix.adjacent[n] {
+				if !members[m] {
+					members[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		frontier = next
+	}
+	return ix.subgraph(members)
+}
+
+// Section returns the items of a section and the relations between them.
+func (ix *Index) Section(sectionID int) Graph {
+	members := make(map[int]bool)
+	for id, n := range ix.nodes {
+		if n.SectionID == sectionID {
+			members[id] = true
+		}
+	}
+	return ix.subgraph(members)
+}
+
+func (ix *Index) subgraph(members map[int]bool) Graph {
+	g := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for id := range members {
+		g.Nodes = append(g.Nodes, ix.nodes[id])
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+
+	for _, e := range ix.edges {
+		if members[e.From] && members[e.To] {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	return g
+}
+
+// edgeStyles gives each relation type its own look in DOT output.
+var edgeStyles = map[string]string{
+	models.ItemRelationCounters:      `color="firebrick"`,
+	models.ItemRelationIsCounteredBy: `color="firebrick", style="dashed"`,
+	models.ItemRelationFollowUp:      `color="black"`,
+	models.ItemRelationIsRemedyOf:    `color="royalblue"`,
+}
+
+// WriteDOT writes g as a Graphviz digraph named name, laid out left to right.
+// Nodes are labelled with item titles and edges with their relation type.
+func WriteDOT(w io.Writer, g Graph, name string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %d [label=%s];\n", n.ID, strconv.Quote(n.Title))
+	}
+	for _, e := range g.Edges {
+		attrs := "label=" + strconv.Quote(e.Type)
+		if style, ok := edgeStyles[e.Type]; ok {
+			attrs += ", " + style
+		}
+		if e.Note != "" {
+			attrs += ", tooltip=" + strconv.Quote(e.Note)
+		}
+		fmt.Fprintf(&b, "  %d -> %d [%s];\n", e.From, e.To, attrs)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
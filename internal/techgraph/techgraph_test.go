@@ -0,0 +1,105 @@
+package techgraph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hema-lessons/internal/models"
+)
+
+func testItems() []models.Item {
+	rel := func(t string, id int) []models.ItemRelation {
+		return []models.ItemRelation{{Type: t, ItemID: id}}
+	}
+	return []models.Item{
+		{ID: 3, SectionID: 1, Title: "Scholar", Relations: rel(models.ItemRelationFollowUp, 4)},
+		{ID: 1, SectionID: 1, Title: "Attack"},
+		{ID: 2, SectionID: 1, Title: "Remedy Master", Relations: []models.ItemRelation{
+			{Type: models.ItemRelationIsRemedyOf, ItemID: 1},
+			{Type: models.ItemRelationFollowUp, ItemID: 3, Note: "First scholar"},
+		}},
+		{ID: 4, SectionID: 1, Title: "Second Scholar"},
+		{ID: 5, SectionID: 2, Title: "Counter-Master", Relations: []models.ItemRelation{
+			{Type: models.ItemRelationCounters, ItemID: 2},
+			{Type: models.ItemRelationCounters, ItemID: 99}, // unknown item
+		}},
+	}
+}
+
+func nodeIDs(g Graph) []int {
+	ids := []int{}
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestIndex_Neighbourhood(t *testing.T) {
+	ix := New(testItems())
+
+	tests := []struct {
+		name      string
+		id, depth int
+		wantNodes []int
+		wantEdges int
+	}{
+		{"depth 0 is the item alone", 2, 0, []int{2}, 0},
+		{"depth 1 follows edges both ways", 2, 1, []int{1, 2, 3, 5}, 3},
+		{"depth 2", 2, 2, []int{1, 2, 3, 4, 5}, 4},
+		{"unknown item", 42, 2, []int{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ix.Neighbourhood(tt.id, tt.depth)
+			if ids := nodeIDs(g); !reflect.DeepEqual(ids, tt.wantNodes) {
+				t.Errorf("expected nodes %v, got %v", tt.wantNodes, ids)
+			}
+			if len(g.Edges) != tt.wantEdges {
+				t.Errorf("expected %d edges, got %d: %+v", tt.wantEdges, len(g.Edges), g.Edges)
+			}
+		})
+	}
+}
+
+func TestIndex_Section(t *testing.T) {
+	g := New(testItems()).Section(1)
+
+	if ids := nodeIDs(g); !reflect.DeepEqual(ids, []int{1, 2, 3, 4}) {
+		t.Errorf("expected nodes [1 2 3 4], got %v", ids)
+	}
+	want := []Edge{
+		{From: 2, To: 1, Type: models.ItemRelationIsRemedyOf},
+		{From: 2, To: 3, Type: models.ItemRelationFollowUp, Note: "First scholar"},
+		{From: 3, To: 4, Type: models.ItemRelationFollowUp},
+	}
+	if !reflect.DeepEqual(g.Edges, want) {
+		t.Errorf("unexpected edges\nexpected: %+v\ngot:      %+v", want, g.Edges)
+	}
+
+	if g := New(testItems()).Section(9); g.Nodes == nil || g.Edges == nil || len(g.Nodes) != 0 {
+		t.Errorf("expected an empty, non-nil graph, got %#v", g)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := New(testItems()).Neighbourhood(5, 1)
+
+	var b strings.Builder
+	if err := WriteDOT(&b, g, `Fiore's "plays"`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `digraph "Fiore's \"plays\"" {
+  rankdir=LR;
+  node [shape=box, style=rounded];
+  2 [label="Remedy Master"];
+  5 [label="Counter-Master"];
+  5 -> 2 [label="counters", color="firebrick"];
+}
+`
+	if b.String() != want {
+		t.Errorf("unexpected DOT output\nexpected:\n%s\ngot:\n%s", want, b.String())
+	}
+}
@@ -2,26 +2,39 @@ package testutil
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
 
 	"hema-lessons/internal/models"
 	"hema-lessons/internal/store"
+	"hema-lessons/internal/taxonomy"
 )
 
-// TestAuthors returns a set of authors for testing.
+// TestAuthors returns a set of authors for testing. Author 1 taught author 2,
+// whose work author 3 glossed; author 3 also continues author 1's tradition.
 func TestAuthors() []models.Author {
 	return []models.Author{
-		{ID: 1, Name: "Test Author 1", Bio: "First test author", BirthYear: intPtr(1300), DeathYear: intPtr(1380)},
-		{ID: 2, Name: "Test Author 2", Bio: "Second test author", BirthYear: intPtr(1350), DeathYear: intPtr(1420)},
+		{ID: 1, Name: "Test Author 1", Bio: "First test author", BirthYear: intPtr(1300), DeathYear: intPtr(1380),
+			Relations: []models.AuthorRelation{
+				{Type: models.RelationTeacherOf, AuthorID: 2},
+				{Type: models.RelationTradition, AuthorID: 3, Note: "Later follower"},
+			}},
+		{ID: 2, Name: "Test Author 2", Bio: "Second test author", BirthYear: intPtr(1350), DeathYear: intPtr(1420),
+			Relations: []models.AuthorRelation{
+				{Type: models.RelationGlossedBy, AuthorID: 3},
+			}},
 		{ID: 3, Name: "Test Author 3", Bio: "Third test author", BirthYear: intPtr(1400), DeathYear: intPtr(1470)},
 	}
 }
 
 // TestResources returns 5 resources for testing. Resources A and B belong to author 1,
-// C and D to author 2, E to author 3.
+// C and D to author 2, E to author 3. Book B was once titled "Second Book".
 func TestResources() []models.Resource {
 	return []models.Resource{
 		{ID: 1, AuthorID: intPtr(1), Title: "Book A", Description: "First test book", PublicationYear: intPtr(1400)},
-		{ID: 2, AuthorID: intPtr(1), Title: "Book B", Description: "Second test book", PublicationYear: intPtr(1410)},
+		{ID: 2, AuthorID: intPtr(1), Title: "Book B", Description: "Second test book", PublicationYear: intPtr(1410),
+			FormerSlugs: []string{"second-book"}},
 		{ID: 3, AuthorID: intPtr(2), Title: "Book C", Description: "Third test book", PublicationYear: intPtr(1420)},
 		{ID: 4, AuthorID: intPtr(2), Title: "Book D", Description: "Fourth test book", PublicationYear: intPtr(1430)},
 		{ID: 5, AuthorID: intPtr(3), Title: "Book E", Description: "Fifth test book", PublicationYear: intPtr(1440)},
@@ -31,10 +44,14 @@ func TestResources() []models.Resource {
 // TestSections returns sections for testing.
 // Resource 1 has 3 root sections; resource 2 has 2 root sections.
 // Section 1 also has 1 child section (id=6) to test arbitrary nesting.
+// Section 1 is tagged dagger/unarmoured and section 2 longsword/armoured;
+// section 6 inherits section 1's tags. Section 2 was once titled "Part Two".
 func TestSections() []models.Section {
 	return []models.Section{
-		{ID: 1, ResourceID: 1, ParentID: nil, Kind: "chapter", Title: "Chapter 1", Description: "First chapter of Book A", Position: 1},
-		{ID: 2, ResourceID: 1, ParentID: nil, Kind: "chapter", Title: "Chapter 2", Description: "Second chapter of Book A", Position: 2},
+		{ID: 1, ResourceID: 1, ParentID: nil, Kind: "chapter", Title: "Chapter 1", Description: "First chapter of Book A", Position: 1,
+			Tags: &models.Tags{Weapons: []string{"dagger"}, Armour: "unarmoured"}},
+		{ID: 2, ResourceID: 1, ParentID: nil, Kind: "chapter", Title: "Chapter 2", Description: "Second chapter of Book A", Position: 2,
+			Tags: &models.Tags{Weapons: []string{"longsword"}, Armour: "armoured"}, FormerSlugs: []string{"part-two"}},
 		{ID: 3, ResourceID: 1, ParentID: nil, Kind: "chapter", Title: "Chapter 3", Description: "Third chapter of Book A", Position: 3},
 		{ID: 4, ResourceID: 2, ParentID: nil, Kind: "chapter", Title: "Introduction", Description: "First chapter of Book B", Position: 1},
 		{ID: 5, ResourceID: 2, ParentID: nil, Kind: "chapter", Title: "Advanced Techniques", Description: "Second chapter of Book B", Position: 2},
@@ -43,15 +60,54 @@ func TestSections() []models.Section {
 }
 
 // TestItems returns items for testing.
-// Section 1 has 3 items; section 2 has 2 items.
+// Section 1 has 3 items; section 2 has 2 items; nested section 6 has 1 item.
+// Items 1 and 6 are locks, item 2 a thrust and item 4 a cut from posta di donna.
+// Item 1 is followed up by item 2 and item 2 by item 3; item 4 counters
+// item 1 and item 5 is the remedy of item 4. Item 4 was once titled "First Move".
 func TestItems() []models.Item {
 	attrs := json.RawMessage(`{"instructions":"Step 1, Step 2"}`)
 	return []models.Item{
-		{ID: 1, SectionID: 1, Kind: "technique", Title: "Technique 1", Description: "First technique", Position: 1, Attributes: attrs},
-		{ID: 2, SectionID: 1, Kind: "technique", Title: "Technique 2", Description: "Second technique", Position: 2, Attributes: attrs},
+		{ID: 1, SectionID: 1, Kind: "technique", Title: "Technique 1", Description: "First technique", Position: 1, Attributes: attrs,
+			Tags:      &models.Tags{Actions: []string{"lock"}},
+			Relations: []models.ItemRelation{{Type: models.ItemRelationFollowUp, ItemID: 2, Note: "Continue into the second play"}}},
+		{ID: 2, SectionID: 1, Kind: "technique", Title: "Technique 2", Description: "Second technique", Position: 2, Attributes: attrs,
+			Tags:      &models.Tags{Actions: []string{"thrust"}},
+			Relations: []models.ItemRelation{{Type: models.ItemRelationFollowUp, ItemID: 3}}},
 		{ID: 3, SectionID: 1, Kind: "technique", Title: "Technique 3", Description: "Third technique", Position: 3, Attributes: attrs},
-		{ID: 4, SectionID: 2, Kind: "technique", Title: "Basic Move", Description: "A basic move", Position: 1, Attributes: attrs},
-		{ID: 5, SectionID: 2, Kind: "technique", Title: "Advanced Move", Description: "An advanced move", Position: 2, Attributes: attrs},
+		{ID: 4, SectionID: 2, Kind: "technique", Title: "Basic Move", Description: "A basic move", Position: 1, Attributes: attrs,
+			Tags:        &models.Tags{Guards: []string{"posta-di-donna"}, Actions: []string{"cut"}},
+			Relations:   []models.ItemRelation{{Type: models.ItemRelationCounters, ItemID: 1}},
+			FormerSlugs: []string{"first-move"}},
+		{ID: 5, SectionID: 2, Kind: "technique", Title: "Advanced Move", Description: "An advanced move", Position: 2, Attributes: attrs,
+			Relations: []models.ItemRelation{{Type: models.ItemRelationIsRemedyOf, ItemID: 4}}},
+		{ID: 6, SectionID: 6, Kind: "technique", Title: "Nested Technique", Description: "A technique in a sub-section", Position: 1, Attributes: attrs,
+			Tags: &models.Tags{Actions: []string{"lock"}}},
+	}
+}
+
+// TestConcordance returns concordance links between the test items. Item 1 is
+// the same technique as item 4, which has a variant in item 2; item 3
+// counters item 4 and item 5 is a prerequisite of item 1.
+func TestConcordance() []models.ConcordanceLink {
+	return []models.ConcordanceLink{
+		{ItemID: 1, RelatedItemID: 4, Type: models.ConcordanceSame, Note: "Same play in both books"},
+		{ItemID: 4, RelatedItemID: 2, Type: models.ConcordanceVariant},
+		{ItemID: 3, RelatedItemID: 4, Type: models.ConcordanceCounter},
+		{ItemID: 5, RelatedItemID: 1, Type: models.ConcordancePrerequisite},
+	}
+}
+
+// TestTaxonomy returns the terms the test fixtures are tagged with, plus a
+// few unused ones.
+func TestTaxonomy() *taxonomy.Taxonomy {
+	return &taxonomy.Taxonomy{
+		Weapons: []taxonomy.Term{{Slug: "dagger", Name: "Dagger"}, {Slug: "longsword", Name: "Longsword"}, {Slug: "spear", Name: "Spear"}},
+		Guards:  []taxonomy.Term{{Slug: "posta-di-donna", Name: "Posta di Donna"}},
+		Actions: []taxonomy.Term{
+			{Slug: "cut", Name: "Cut"}, {Slug: "thrust", Name: "Thrust"}, {Slug: "bind", Name: "Bind"},
+			{Slug: "throw", Name: "Throw"}, {Slug: "lock", Name: "Lock"},
+		},
+		Armour: []taxonomy.Term{{Slug: "armoured", Name: "Armoured"}, {Slug: "unarmoured", Name: "Unarmoured"}},
 	}
 }
 
@@ -62,12 +118,14 @@ func NewTestStore() *store.Store {
 		TestResources(),
 		TestSections(),
 		TestItems(),
+		TestConcordance(),
+		TestTaxonomy(),
 	)
 }
 
 // NewEmptyStore creates an empty Store for testing empty-result scenarios.
 func NewEmptyStore() *store.Store {
-	return store.NewFromData(nil, nil, nil, nil)
+	return store.NewFromData(nil, nil, nil, nil, nil, TestTaxonomy())
 }
 
 // NewStoreWithAuthorsAndResources creates a Store with only authors and resources (no sections/items).
@@ -77,6 +135,8 @@ func NewStoreWithAuthorsAndResources() *store.Store {
 		TestResources(),
 		nil,
 		nil,
+		nil,
+		TestTaxonomy(),
 	)
 }
 
@@ -87,9 +147,63 @@ func NewStoreWithAuthorsResourcesSections() *store.Store {
 		TestResources(),
 		TestSections(),
 		nil,
+		nil,
+		TestTaxonomy(),
 	)
 }
 
 func intPtr(v int) *int {
 	return &v
 }
+
+// NewTestSQLiteStore creates a SQLiteStore in a temporary directory, seeded with
+// the same data as NewTestStore. The database is closed when the test ends.
+func NewTestSQLiteStore(tb testing.TB) *store.SQLiteStore {
+	tb.Helper()
+
+	db, err := store.OpenSQLite(filepath.Join(tb.TempDir(), "test.db"))
+	if err != nil {
+		tb.Fatalf("failed to open sqlite store: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	if err := db.ImportFrom(NewTestStore()); err != nil {
+		tb.Fatalf("failed to import test data: %v", err)
+	}
+
+	return db
+}
+
+// WriteDataDir writes the test fixtures as JSON data files into a temporary
+// directory and returns its path, for loading with store.Load(os.DirFS(dir)).
+func WriteDataDir(tb testing.TB) string {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	files := map[string]interface{}{
+		"authors.json":     TestAuthors(),
+		"resources.json":   TestResources(),
+		"sections.json":    TestSections(),
+		"items.json":       TestItems(),
+		"taxonomy.json":    TestTaxonomy(),
+		"concordance.json": TestConcordance(),
+	}
+	for name, v := range files {
+		WriteDataFile(tb, dir, name, v)
+	}
+
+	return dir
+}
+
+// WriteDataFile marshals v as JSON into dir/name.
+func WriteDataFile(tb testing.TB, dir, name string, v interface{}) {
+	tb.Helper()
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		tb.Fatalf("failed to marshal %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		tb.Fatalf("failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,122 @@
+package testutil
+
+import (
+	"errors"
+
+	"hema-lessons/internal/models"
+	"hema-lessons/internal/pagination"
+	"hema-lessons/internal/search"
+	"hema-lessons/internal/store"
+	"hema-lessons/internal/suggest"
+	"hema-lessons/internal/taxonomy"
+	"hema-lessons/internal/techgraph"
+)
+
+// ErrBackend is the error returned by every FailingRepository method.
+var ErrBackend = errors.New("backend unavailable")
+
+// FailingRepository is a ContentRepository whose every call fails, for testing
+// how handlers surface backend errors.
+type FailingRepository struct{}
+
+var _ store.ContentRepository = FailingRepository{}
+
+func (r FailingRepository) View() store.ContentRepository {
+	return r
+}
+
+func (FailingRepository) ListAuthors(pagination.Params) ([]models.Author, int, error) {
+	return nil, 0, ErrBackend
+}
+
+func (FailingRepository) GetAuthorByID(int) (*models.Author, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListAllAuthors() ([]models.Author, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListResources(pagination.Params) ([]store.ResourceWithAuthor, int, error) {
+	return nil, 0, ErrBackend
+}
+
+func (FailingRepository) GetResourceByID(int) (*store.ResourceWithAuthor, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) GetResourceBySlug(string) (*store.ResourceWithAuthor, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ResourceExists(int) (bool, error) {
+	return false, ErrBackend
+}
+
+func (FailingRepository) ListResourcesByAuthorID(int) ([]store.ResourceWithAuthor, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ResourceTree(int) (*store.ResourceTree, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListRootSectionsByResourceID(int) ([]models.Section, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) GetSectionByID(int) (*models.Section, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) GetSectionBySlug(int, string) (*models.Section, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListChildSections(int) ([]models.Section, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListItemsBySectionID(int) ([]models.Item, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) GetItemByID(int) (*models.Item, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) GetItemBySlug(int, string) (*models.Item, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ItemGraph(int, int) (*techgraph.Graph, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) SectionGraph(int) (*techgraph.Graph, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListConcordanceLinks() ([]models.ConcordanceLink, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) Search(string) ([]search.Result, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) Suggest(string, int) ([]suggest.Entry, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) TagSummary() (*taxonomy.Summary, error) {
+	return nil, ErrBackend
+}
+
+func (FailingRepository) ListItemsByTags(taxonomy.Filter, pagination.Params) ([]store.TaggedItem, int, error) {
+	return nil, 0, ErrBackend
+}
+
+func (FailingRepository) Version() (store.Version, error) {
+	return store.Version{}, ErrBackend
+}
@@ -0,0 +1,53 @@
+// Package textnorm normalises titles and free text for matching and URLs.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ligatures are letters that do not decompose into a base letter plus marks.
+var ligatures = strings.NewReplacer(
+	"ß", "ss",
+	"æ", "ae", "Æ", "AE",
+	"œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L",
+)
+
+// Fold lower-cases s and strips diacritics, so "Übung" and "übung" both fold
+// to "ubung" and "Colpo di villano" matches "colpo".
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, ligatures.Replace(s))
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// Slugify turns a title into the URL and folder slug used under assets/books:
+// "Posta di Donna (Woman's Guard)" becomes "posta-di-donna-womans-guard".
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range Fold(title) {
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		default:
+			pendingDash = true
+		}
+	}
+	return b.String()
+}
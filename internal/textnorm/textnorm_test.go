@@ -0,0 +1,32 @@
+package textnorm
+
+import "testing"
+
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Colpo di Villano":        "colpo di villano",
+		"Übung macht den Meister": "ubung macht den meister",
+		"Meßer":                   "messer",
+		"Perché":                  "perche",
+	}
+	for in, want := range tests {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Posta di Finestra (Window Guard)":    "posta-di-finestra-window-guard",
+		"Posta di Donna (Woman's Guard)":      "posta-di-donna-womans-guard",
+		"Colpo di Villano (Peasant’s Strike)": "colpo-di-villano-peasants-strike",
+		"Zornhau — Wrath Strike":              "zornhau-wrath-strike",
+		"  Fior di Battaglia ":                "fior-di-battaglia",
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}